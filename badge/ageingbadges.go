@@ -0,0 +1,116 @@
+package badge
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/narqo/go-badge"
+)
+
+// AgeingThresholds are the number of days after which issues and pull
+// requests are considered neglected.
+type AgeingThresholds struct {
+	OldestIssue    int `yaml:"oldest-issue,omitempty"`
+	PullRequestAge int `yaml:"pullrequest-age,omitempty"`
+	ReviewWait     int `yaml:"review-wait,omitempty"`
+}
+
+var Ageing = AgeingThresholds{OldestIssue: 365, PullRequestAge: 30, ReviewWait: 7}
+
+// ticket is an open issue or pull request of any forge.
+type ticket struct {
+	Created     time.Time
+	PullRequest bool
+	Labeled     bool
+	Assigned    bool
+	Reviewed    bool
+}
+
+func ageInDays(t time.Time) int {
+	return int(time.Since(t).Hours() / 24)
+}
+
+// ageColor is green below half of the threshold, yellow below the threshold
+// and red above.
+func ageColor(days, threshold int) badge.Color {
+	switch {
+	case days < threshold/2:
+		return badge.ColorBrightgreen
+	case days < threshold:
+		return badge.ColorYellow
+	}
+	return badge.ColorRed
+}
+
+func oldestIssueBadge(project Project, link string, tickets []ticket) *Badge {
+	oldest := -1
+	for _, t := range tickets {
+		if !t.PullRequest && ageInDays(t.Created) > oldest {
+			oldest = ageInDays(t.Created)
+		}
+	}
+	if oldest < 0 {
+		return svgBadge(project.Hoster, project.Name, "oldest-issue", "oldest issue", "none", badge.ColorBrightgreen, link, nil)
+	}
+	b := svgBadge(project.Hoster, project.Name, "oldest-issue", "oldest issue", fmt.Sprintf("%d days", oldest), ageColor(oldest, Ageing.OldestIssue), link, nil)
+	b.Value = oldest
+	return b
+}
+
+func pullRequestAgeBadge(project Project, label, link string, tickets []ticket) *Badge {
+	var ages []int
+	for _, t := range tickets {
+		if t.PullRequest {
+			ages = append(ages, ageInDays(t.Created))
+		}
+	}
+	if len(ages) == 0 {
+		return svgBadge(project.Hoster, project.Name, "pr-age", label, "none", badge.ColorBrightgreen, link, nil)
+	}
+
+	sort.Ints(ages)
+	median := ages[len(ages)/2]
+	if len(ages)%2 == 0 {
+		median = (ages[len(ages)/2-1] + ages[len(ages)/2]) / 2
+	}
+	b := svgBadge(project.Hoster, project.Name, "pr-age", label, fmt.Sprintf("%d days", median), ageColor(median, Ageing.PullRequestAge), link, nil)
+	b.Value = median
+	return b
+}
+
+func reviewWaitBadge(project Project, label, link string, tickets []ticket) *Badge {
+	waiting := 0
+	for _, t := range tickets {
+		if t.PullRequest && !t.Reviewed && ageInDays(t.Created) > Ageing.ReviewWait {
+			waiting++
+		}
+	}
+
+	color := badge.ColorBrightgreen
+	if waiting > 0 {
+		color = badge.ColorRed
+	}
+	b := svgBadge(project.Hoster, project.Name, "review-wait", label, fmt.Sprint(waiting), color, link, nil)
+	b.Value = waiting
+	return b
+}
+
+// untriagedBadge counts the open issues that have neither labels nor
+// assignees.
+func untriagedBadge(project Project, link string, tickets []ticket) *Badge {
+	untriaged := 0
+	for _, t := range tickets {
+		if !t.PullRequest && !t.Labeled && !t.Assigned {
+			untriaged++
+		}
+	}
+
+	color := badge.ColorBrightgreen
+	if untriaged > 0 {
+		color = badge.ColorYellow
+	}
+	b := svgBadge(project.Hoster, project.Name, "untriaged", "untriaged issues", fmt.Sprint(untriaged), color, link, nil)
+	b.Value = untriaged
+	return b
+}
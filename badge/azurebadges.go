@@ -1,8 +1,83 @@
 package badge
 
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
 var isAzure = func(p Project) bool { return p.AzureDefinitionID != "" }
 
 func InitAzureBadges() {
-	badges["azure-pipeline"] = markdownBadge("https://img.shields.io/azure-devops/build/{{.AzureOrganization}}/{{.AzureProject}}/{{.AzureDefinitionID}}", "https://dev.azure.com/{{.AzureOrganization}}/{{.AzureProject}}/_build?definitionId={{.AzureDefinitionID}}&_a=summary", isAzure)
+	badges["azure-pipeline"] = azurePipeline
 	badges["azure-coverage"] = markdownBadge("https://img.shields.io/azure-devops/coverage/{{.AzureOrganization}}/{{.AzureProject}}/{{.AzureDefinitionID}}", "{{.URL}}", isAzure)
 }
+
+type azureBuilds struct {
+	Value []struct {
+		Status     string    `json:"status"`
+		Result     string    `json:"result"`
+		StartTime  time.Time `json:"startTime"`
+		FinishTime time.Time `json:"finishTime"`
+		Links      struct {
+			Web struct {
+				Href string `json:"href"`
+			} `json:"web"`
+		} `json:"_links"`
+	} `json:"value"`
+}
+
+// azurePipeline shows the state of the latest Azure DevOps build of the
+// configured definition on the default branch.
+func azurePipeline(ctx context.Context, project Project) *Badge {
+	if !isAzure(project) {
+		return nil
+	}
+
+	query := url.Values{}
+	query.Set("definitions", project.AzureDefinitionID)
+	query.Set("$top", "1")
+	query.Set("api-version", "6.0")
+	if project.DefaultBranch != "" {
+		query.Set("branchName", "refs/heads/"+project.DefaultBranch)
+	}
+	u := fmt.Sprintf("https://dev.azure.com/%s/%s/_apis/build/builds?%s", url.PathEscape(project.AzureOrganization), url.PathEscape(project.AzureProject), query.Encode())
+
+	resp, err := httpGet(ctx, "azure", u)
+	if err != nil {
+		return errorBadge("azure-pipeline", project, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errorBadge("azure-pipeline", project, newStatusError("azure builds", resp))
+	}
+
+	var builds azureBuilds
+	if err := json.NewDecoder(resp.Body).Decode(&builds); err != nil {
+		return errorBadge("azure-pipeline", project, err)
+	}
+	if len(builds.Value) == 0 {
+		return nil
+	}
+
+	build := builds.Value[0]
+	pipeline := &Pipeline{State: PipelineUnknown, Duration: build.FinishTime.Sub(build.StartTime), URL: build.Links.Web.Href}
+	switch {
+	case build.Status == "notStarted" || build.Status == "postponed":
+		pipeline.State = PipelinePending
+		pipeline.Duration = 0
+	case build.Status != "completed":
+		pipeline.State = PipelineRunning
+		pipeline.Duration = time.Since(build.StartTime)
+	case build.Result == "succeeded":
+		pipeline.State = PipelinePassed
+	case build.Result == "failed" || build.Result == "partiallySucceeded":
+		pipeline.State = PipelineFailed
+	case build.Result == "canceled":
+		pipeline.State = PipelineCanceled
+	}
+	return pipelineBadge("azure-pipeline", project, pipeline)
+}
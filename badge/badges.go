@@ -2,21 +2,36 @@ package badge
 
 import (
 	"bytes"
+	"context"
+	"crypto/tls"
 	"fmt"
 	"html/template"
 	"io/ioutil"
-	"log"
+	"net/http"
 	"os"
 	"path/filepath"
+	"sync"
+	"time"
 
 	"github.com/narqo/go-badge"
 )
 
 type Badge struct {
-	URL   string `yaml:"url,omitempty"`
-	Link  string `yaml:"link,omitempty"`
-	Title string `yaml:"title,omitempty"`
-	Error error  `yaml:"error,omitempty"`
+	URL     string      `yaml:"url,omitempty"`
+	Link    string      `yaml:"link,omitempty"`
+	Title   string      `yaml:"title,omitempty"`
+	Error   error       `yaml:"error,omitempty"`
+	Value   interface{} `yaml:"value,omitempty"`
+	Retries int         `yaml:"retries,omitempty"`
+	// Stale is the time of the shown result if the badge failed and its
+	// last good result is shown instead.
+	Stale time.Time `yaml:"stale,omitempty"`
+	// Reports are the files written next to the badge, like license.txt.
+	Reports map[string][]byte `yaml:"-"`
+
+	label   string
+	message string
+	color   badge.Color
 }
 
 func (b *Badge) ToMarkdown() string {
@@ -37,13 +52,18 @@ type Project struct {
 	GoImportPath      string            `yaml:"goimportpath,omitempty"`
 	Workflow          string            `yaml:"workflow,omitempty"`
 	URL               string            `yaml:"url,omitempty"`
+	DefaultBranch     string            `yaml:"default-branch,omitempty"`
 	Disable           []string          `yaml:"disable,omitempty"`
 	Enable            []string          `yaml:"enable,omitempty"`
-	Token             string            `yaml:"token,omitempty"`
+	SARIF             string            `yaml:"sarif,omitempty"`
+	CoverageReport    string            `yaml:"coverage-report,omitempty"`
+	Prereleases       bool              `yaml:"prereleases,omitempty"`
+	ScanHistory       bool              `yaml:"scan-history,omitempty"`
+	TokenFrom         *TokenSource      `yaml:"token-from,omitempty"`
 	IsGitlab          bool              `yaml:"gitlab,omitempty"`
 }
 
-type badgeCreation func(Project) *Badge
+type badgeCreation func(context.Context, Project) *Badge
 
 var badges = map[string]badgeCreation{}
 
@@ -52,48 +72,177 @@ func GetBadge(name string) (badgeCreation, bool) {
 	return val, ok
 }
 
-func svgBadge(hoster, projectname, name, left, right string, color badge.Color, url string, e error) *Badge {
-	if len(right) > 40 {
-		right = right[:35]
+// Costs order the scheduling of badges, cheap badges run first.
+const (
+	CostAPI = iota
+	CostClone
+	CostCommand
+)
+
+var costs = map[string]int{}
+
+// GetCost returns the cost of a badge. Badges without a registered cost only
+// render templates or call APIs.
+func GetCost(name string) int {
+	return costs[name]
+}
+
+func setCost(cost int, names ...string) {
+	for _, name := range names {
+		costs[name] = cost
 	}
-	b, err := badge.RenderBytes(left, right, color)
-	if err != nil {
-		panic(err)
+}
+
+var incremental = map[string]bool{}
+
+// Incremental reports whether a badge only depends on the content of the
+// default branch, so its last result is still valid while the branch does not
+// change.
+func Incremental(name string) bool {
+	return incremental[name]
+}
+
+func setIncremental(names ...string) {
+	for _, name := range names {
+		incremental[name] = true
+	}
+}
+
+var settings = map[string]func() interface{}{}
+
+// setSettings registers the configuration the results of badges depend on,
+// so their last results are not reused after it changed.
+func setSettings(config func() interface{}, names ...string) {
+	for _, name := range names {
+		settings[name] = config
+	}
+}
+
+var httpClients sync.Map
+
+// newHTTPClient returns the client of a provider retrying with its policy.
+// Clients are shared, so connections are reused by all badges. Only the
+// response headers have a fixed timeout, reading the body is bounded by the
+// deadline of the request context, so large clones do not fail.
+func newHTTPClient(provider string) *http.Client {
+	if client, ok := httpClients.Load(provider); ok {
+		return client.(*http.Client)
 	}
-	err = os.MkdirAll(filepath.Join("badges", hoster, projectname), 0777)
+	client := &http.Client{
+		Transport: &retryTransport{
+			provider: provider,
+			base: &http.Transport{
+				Proxy:                 http.ProxyFromEnvironment,
+				TLSClientConfig:       &tls.Config{InsecureSkipVerify: Insecure},
+				TLSHandshakeTimeout:   10 * time.Second,
+				ResponseHeaderTimeout: 10 * time.Second,
+				IdleConnTimeout:       90 * time.Second,
+				MaxIdleConnsPerHost:   8,
+			},
+		},
+	}
+	actual, _ := httpClients.LoadOrStore(provider, client)
+	return actual.(*http.Client)
+}
+
+// httpGet fetches an URL with the HTTP client of the badges. The request is
+// canceled with the context.
+func httpGet(ctx context.Context, provider, u string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
-	err = ioutil.WriteFile(filepath.Join("badges", hoster, projectname, name+".svg"), bytes.ReplaceAll(b, []byte("\n"), []byte("")), 0666)
+	return newHTTPClient(provider).Do(req)
+}
+
+// truncateMessage shortens long messages, so badges keep a usable width.
+func truncateMessage(message string) string {
+	if len(message) > 40 {
+		return message[:35]
+	}
+	return message
+}
+
+func svgBadge(hoster, projectname, name, left, right string, color badge.Color, url string, e error) *Badge {
+	right = truncateMessage(right)
+	err := writeSVG(filepath.Join("badges", hoster, projectname, name+".svg"), left, right, color)
 	if err != nil {
 		panic(err)
 	}
 
 	return &Badge{
-		URL:   fmt.Sprintf("badges/%s/%s/%s.svg", hoster, projectname, name),
-		Link:  url,
-		Title: name,
-		Error: e,
+		URL:     fmt.Sprintf("badges/%s/%s/%s.svg", hoster, projectname, name),
+		Link:    url,
+		Title:   name,
+		Error:   Redact(e),
+		label:   left,
+		message: right,
+		color:   color,
+	}
+}
+
+func writeSVG(path, left, right string, color badge.Color) error {
+	b, err := badge.RenderBytes(left, right, color)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(path), 0777)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, bytes.ReplaceAll(b, []byte("\n"), []byte("")), 0666)
+}
+
+// templateView is the data of badge templates. It only holds public settings
+// of a project, so templates cannot reference credentials.
+type templateView struct {
+	Hoster            string
+	Namespace         string
+	Name              string
+	URL               string
+	DefaultBranch     string
+	GoImportPath      string
+	Workflow          string
+	AzureOrganization string
+	AzureProject      string
+	AzureDefinitionID string
+	Meta              map[string]string
+}
+
+func newTemplateView(project Project) templateView {
+	return templateView{
+		Hoster:            project.Hoster,
+		Namespace:         project.Namespace,
+		Name:              project.Name,
+		URL:               project.URL,
+		DefaultBranch:     project.DefaultBranch,
+		GoImportPath:      project.GoImportPath,
+		Workflow:          project.Workflow,
+		AzureOrganization: project.AzureOrganization,
+		AzureProject:      project.AzureProject,
+		AzureDefinitionID: project.AzureDefinitionID,
+		Meta:              project.Meta,
 	}
 }
 
 func markdownBadge(badge, link string, condition func(p Project) bool) badgeCreation {
-	return func(project Project) *Badge {
+	return func(ctx context.Context, project Project) *Badge {
 		if condition == nil || condition(project) {
 			o := Badge{}
+			view := newTemplateView(project)
 
 			badgebuf, linkbuf := &bytes.Buffer{}, &bytes.Buffer{}
 			badgeurl := template.Must(template.New("badge").Parse(badge))
-			err := badgeurl.Execute(badgebuf, project)
+			err := badgeurl.Execute(badgebuf, view)
 			if err != nil {
-				log.Fatal(err)
+				Log.Fatal("badge template failed", "project", project.URL, "error", err)
 			}
 			o.URL = badgebuf.String()
 
 			linkurl := template.Must(template.New("link").Parse(link))
-			err = linkurl.Execute(linkbuf, project)
+			err = linkurl.Execute(linkbuf, view)
 			if err != nil {
-				log.Fatal(err)
+				Log.Fatal("link template failed", "project", project.URL, "error", err)
 			}
 			o.Link = linkbuf.String()
 			return &o
@@ -0,0 +1,140 @@
+package badge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+// branchResolvers look up the default branch with the forge APIs. They return
+// an empty string for projects of other forges.
+var branchResolvers []func(context.Context, Project) (string, error)
+
+// fallbackBranch is the default branch if it cannot be resolved.
+const fallbackBranch = "master"
+
+// ResolveDefaultBranch sets Project.DefaultBranch unless it is configured
+// already. The forge APIs are asked first, the HEAD of the remote repository
+// is used for all other forges and if the forge API fails. If both fail, the
+// branch is master and the error is returned.
+func ResolveDefaultBranch(ctx context.Context, project Project) (Project, error) {
+	if project.DefaultBranch != "" {
+		return project, nil
+	}
+
+	var resolveErr error
+	for _, resolve := range branchResolvers {
+		branch, err := resolve(ctx, project)
+		if err != nil {
+			resolveErr = err
+			break
+		}
+		if branch != "" {
+			project.DefaultBranch = branch
+			return project, nil
+		}
+	}
+
+	branch, err := remoteHead(ctx, project)
+	if err != nil {
+		// Links and templates keep working with the branch used before the
+		// default branch was resolved.
+		project.DefaultBranch = fallbackBranch
+		if resolveErr != nil {
+			return project, resolveErr
+		}
+		return project, err
+	}
+	project.DefaultBranch = branch
+	return project, nil
+}
+
+var refsCache sync.Map
+
+// remoteRefs lists the references of the remote repository like
+// git ls-remote.
+func remoteRefs(ctx context.Context, project Project) ([]*plumbing.Reference, error) {
+	if refs, ok := refsCache.Load(project.URL); ok {
+		return refs.([]*plumbing.Reference), nil
+	}
+
+	installHTTPClient()
+
+	auth, err := basicAuth(project)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	// Remote.List takes no context, it is bounded by the HTTP client timeout.
+	remote := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{Name: "origin", URLs: []string{project.URL}})
+	refs, err := remote.List(&git.ListOptions{Auth: auth})
+	if err != nil {
+		return nil, err
+	}
+	refsCache.Store(project.URL, refs)
+	return refs, nil
+}
+
+// remoteHead returns the branch the HEAD of the remote repository points to.
+func remoteHead(ctx context.Context, project Project) (string, error) {
+	refs, err := remoteRefs(ctx, project)
+	if err != nil {
+		return "", err
+	}
+	for _, ref := range refs {
+		if ref.Name() == plumbing.HEAD && ref.Type() == plumbing.SymbolicReference {
+			return strings.TrimPrefix(ref.Target().String(), "refs/heads/"), nil
+		}
+	}
+	return "", errors.New("remote HEAD is not a branch")
+}
+
+// HeadCommit returns the commit the default branch of a project points to
+// without cloning it.
+func HeadCommit(ctx context.Context, project Project) (string, error) {
+	refs, err := remoteRefs(ctx, project)
+	if err != nil {
+		return "", err
+	}
+	branch := plumbing.HEAD
+	if project.DefaultBranch != "" {
+		branch = plumbing.NewBranchReferenceName(project.DefaultBranch)
+	}
+	for _, ref := range refs {
+		if ref.Name() == branch && ref.Type() == plumbing.SymbolicReference {
+			branch = ref.Target()
+		}
+	}
+	for _, ref := range refs {
+		if ref.Name() == branch && ref.Type() == plumbing.HashReference {
+			return ref.Hash().String(), nil
+		}
+	}
+	return "", fmt.Errorf("branch %s not found", branch.Short())
+}
+
+// updatedResolvers return the time a project was last updated with the forge
+// APIs. They return a zero time for projects of other forges.
+var updatedResolvers []func(context.Context, Project) (time.Time, error)
+
+// ProjectUpdated returns the time of the last change or push the forge of a
+// project reports, or a zero time for other forges.
+func ProjectUpdated(ctx context.Context, project Project) (time.Time, error) {
+	for _, resolve := range updatedResolvers {
+		updated, err := resolve(ctx, project)
+		if err != nil || !updated.IsZero() {
+			return updated, err
+		}
+	}
+	return time.Time{}, nil
+}
@@ -0,0 +1,122 @@
+package badge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/google/go-github/v28/github"
+	"github.com/xanzy/go-gitlab"
+)
+
+// The causes of failed badges, in the order of the error report.
+const (
+	CauseAuth        = "auth"
+	CauseNotFound    = "not found"
+	CauseRateLimit   = "rate limit"
+	CauseTimeout     = "timeout"
+	CauseClone       = "clone failure"
+	CauseMissingTool = "missing tool"
+	CauseOther       = "other"
+)
+
+var Causes = []string{CauseAuth, CauseNotFound, CauseRateLimit, CauseTimeout, CauseClone, CauseMissingTool, CauseOther}
+
+// Remedies suggest how to fix the failures of a cause.
+var Remedies = map[string]string{
+	CauseAuth:        "Check the token of the host in the credentials or the -github and -gitlab flags, it may be expired or miss a scope.",
+	CauseNotFound:    "Check the URL of the project. Private projects need a token with access to them.",
+	CauseRateLimit:   "Use a token, lower -host-workers for the host or run the dashboard less often.",
+	CauseTimeout:     "Raise -badge-timeout or -timeout, or disable slow badges for large projects.",
+	CauseClone:       "Check that the repository can be cloned from this machine and that its default branch exists.",
+	CauseMissingTool: "Install the tool on the machine running the dashboard or disable the badge.",
+	CauseOther:       "See the error of the badge.",
+}
+
+// statusError is an unexpected status of an HTTP response.
+type statusError struct {
+	what   string
+	code   int
+	status string
+}
+
+func newStatusError(what string, resp *http.Response) error {
+	return &statusError{what: what, code: resp.StatusCode, status: resp.Status}
+}
+
+func (e *statusError) Error() string { return fmt.Sprintf("%s: %s", e.what, e.status) }
+
+// tokenError is a token that could not be read.
+type tokenError struct{ err error }
+
+func (e *tokenError) Error() string { return e.err.Error() }
+func (e *tokenError) Unwrap() error { return e.err }
+
+// cloneError is a failed clone of a project.
+type cloneError struct{ err error }
+
+func (e *cloneError) Error() string { return "clone: " + e.err.Error() }
+func (e *cloneError) Unwrap() error { return e.err }
+
+// missingTool reports whether a command could not be started because its
+// executable does not exist.
+func missingTool(err error) bool {
+	var pathErr *os.PathError
+	if errors.As(err, &pathErr) && pathErr.Op == "fork/exec" {
+		return errors.Is(err, os.ErrNotExist)
+	}
+	return errors.Is(err, exec.ErrNotFound)
+}
+
+// statusCode returns the HTTP status of a failed request to a forge or
+// another server.
+func statusCode(err error) int {
+	var githubErr *github.ErrorResponse
+	var gitlabErr *gitlab.ErrorResponse
+	var gitErr *githttp.Err
+	var status *statusError
+	switch {
+	case errors.As(err, &githubErr) && githubErr.Response != nil:
+		return githubErr.Response.StatusCode
+	case errors.As(err, &gitlabErr) && gitlabErr.Response != nil:
+		return gitlabErr.Response.StatusCode
+	case errors.As(err, &gitErr) && gitErr.Response != nil:
+		return gitErr.Response.StatusCode
+	case errors.As(err, &status):
+		return status.code
+	}
+	return 0
+}
+
+// ErrorCause classifies the error of a failed badge.
+func ErrorCause(err error) string {
+	var rateLimit *github.RateLimitError
+	var abuseRateLimit *github.AbuseRateLimitError
+	var token *tokenError
+	var netErr net.Error
+	var clone *cloneError
+	code := statusCode(err)
+	switch {
+	case errors.As(err, &rateLimit) || errors.As(err, &abuseRateLimit) || code == http.StatusTooManyRequests:
+		return CauseRateLimit
+	case errors.As(err, &token) || code == http.StatusUnauthorized || code == http.StatusForbidden,
+		errors.Is(err, transport.ErrAuthenticationRequired) || errors.Is(err, transport.ErrAuthorizationFailed):
+		return CauseAuth
+	case code == http.StatusNotFound || errors.Is(err, transport.ErrRepositoryNotFound):
+		return CauseNotFound
+	case errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled),
+		errors.As(err, &netErr) && netErr.Timeout():
+		return CauseTimeout
+	case missingTool(err):
+		return CauseMissingTool
+	case errors.As(err, &clone):
+		return CauseClone
+	}
+	return CauseOther
+}
@@ -0,0 +1,118 @@
+package badge
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"os"
+	"strings"
+)
+
+// codeownersLocations returns the places the forge of a project reads
+// CODEOWNERS from, in the order of their precedence. Other forges use the
+// order of GitHub.
+func codeownersLocations(project Project) []string {
+	if isGitLab(project) {
+		return []string{"CODEOWNERS", "docs/CODEOWNERS", ".gitlab/CODEOWNERS"}
+	}
+	locations := []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+	if !isGitHub(project) {
+		locations = append(locations, ".gitlab/CODEOWNERS")
+	}
+	return locations
+}
+
+// topicResolvers list the topics of a project with the forge APIs. They
+// return nil for projects of other forges.
+var topicResolvers []func(context.Context, Project) ([]string, error)
+
+// propertyResolvers list the custom properties of a project with the forge
+// APIs. They return nil for projects of other forges.
+var propertyResolvers []func(context.Context, Project) (map[string]string, error)
+
+// ownerProperty is the custom property declaring the owner of a project.
+const ownerProperty = "owner"
+
+// ownerTopicPrefix marks topics like "owner-team-a" as owner declarations.
+const ownerTopicPrefix = "owner-"
+
+// codeowner returns the owner of the catch-all rule of the CODEOWNERS file in
+// the clone and the path of the file. It returns an empty owner if there is no
+// such rule.
+func codeowner(ctx context.Context, project Project) (string, string, error) {
+	projectPath, err := download(ctx, project)
+	if err != nil {
+		return "", "", err
+	}
+
+	for _, location := range codeownersLocations(project) {
+		content, err := readCloneFile(projectPath, location)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", "", err
+		}
+		return catchAllOwner(content), location, nil
+	}
+	return "", "", nil
+}
+
+// catchAllOwner returns the first owner of the last rule matching every file.
+func catchAllOwner(content []byte) string {
+	owner := ""
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "*", "/", "/*", "/**", "**":
+			owner = fields[1]
+		}
+	}
+	return owner
+}
+
+// forgeOwner returns the owner declared by the owner custom property or an
+// owner topic of the project.
+func forgeOwner(ctx context.Context, project Project) (string, error) {
+	for _, resolve := range propertyResolvers {
+		properties, err := resolve(ctx, project)
+		if err != nil {
+			return "", err
+		}
+		if owner := properties[ownerProperty]; owner != "" {
+			return owner, nil
+		}
+	}
+	for _, resolve := range topicResolvers {
+		topics, err := resolve(ctx, project)
+		if err != nil {
+			return "", err
+		}
+		for _, topic := range topics {
+			if strings.HasPrefix(topic, ownerTopicPrefix) {
+				return strings.TrimPrefix(topic, ownerTopicPrefix), nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// fileURL links to a file on the default branch in the web interface of the
+// forge.
+func fileURL(project Project, path string) string {
+	switch {
+	case isGitHub(project):
+		return project.URL + "/blob/" + project.DefaultBranch + "/" + path
+	case isGitLab(project):
+		return project.URL + "/-/blob/" + project.DefaultBranch + "/" + path
+	}
+	return project.URL
+}
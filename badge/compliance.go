@@ -0,0 +1,49 @@
+package badge
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/narqo/go-badge"
+)
+
+// complianceCheck is a single repository setting checked by the compliance
+// badges.
+type complianceCheck struct {
+	Name   string
+	Passed bool
+	Detail string
+}
+
+// complianceBadge summarizes the checks in a single badge and writes the
+// details to a report next to it.
+func complianceBadge(ctx context.Context, project Project, checks []complianceCheck) *Badge {
+	failed := 0
+	var report bytes.Buffer
+	for _, check := range checks {
+		mark := "x"
+		if !check.Passed {
+			mark = " "
+			failed++
+		}
+		fmt.Fprintf(&report, "[%s] %s", mark, check.Name)
+		if check.Detail != "" {
+			fmt.Fprintf(&report, " (%s)", check.Detail)
+		}
+		report.WriteString("\n")
+	}
+
+	complianceLog := filepath.Join("badges", project.Hoster, project.Name, "compliance.txt")
+	_ = writeReport(ctx, complianceLog, report.Bytes())
+
+	var b *Badge
+	if failed == 0 {
+		b = svgBadge(project.Hoster, project.Name, "compliance", "compliance", "compliant", badge.ColorBrightgreen, complianceLog, nil)
+	} else {
+		b = svgBadge(project.Hoster, project.Name, "compliance", "compliance", fmt.Sprintf("%d of %d failed", failed, len(checks)), badge.ColorRed, complianceLog, nil)
+	}
+	b.Value = failed
+	return b
+}
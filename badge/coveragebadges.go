@@ -0,0 +1,232 @@
+package badge
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/narqo/go-badge"
+)
+
+// CoverageThresholds color the coverage badge: red below Red, yellow below
+// Yellow and green above.
+type CoverageThresholds struct {
+	Red    float64 `yaml:"red,omitempty"`
+	Yellow float64 `yaml:"yellow,omitempty"`
+}
+
+var Coverage = CoverageThresholds{Red: 50, Yellow: 80}
+
+// coverageResolvers read the coverage from forge APIs. They return nil for
+// projects of other forges or without coverage data.
+var coverageResolvers []func(context.Context, Project) (*coverageResult, error)
+
+type coverageResult struct {
+	Percent float64
+	Link    string
+}
+
+// wellKnownCoverageReports are looked up in the clone if neither a report is
+// configured nor a forge API knows the coverage.
+var wellKnownCoverageReports = []string{"coverage.xml", "cobertura.xml", "lcov.info", "coverage/lcov.info", "cover.out", "coverage.out"}
+
+func InitCoverageBadges() {
+	badges["coverage"] = coverage
+	setCost(CostClone, "coverage")
+}
+
+func coverage(ctx context.Context, project Project) *Badge {
+	result, err := findCoverage(ctx, project)
+	if err != nil {
+		return errorBadge("coverage", project, err)
+	}
+	if result == nil {
+		return nil
+	}
+
+	color := badge.ColorBrightgreen
+	switch {
+	case result.Percent < Coverage.Red:
+		color = badge.ColorRed
+	case result.Percent < Coverage.Yellow:
+		color = badge.ColorYellow
+	}
+
+	b := svgBadge(project.Hoster, project.Name, "coverage", "coverage", fmt.Sprintf("%.1f%%", result.Percent), color, result.Link, nil)
+	b.Value = result.Percent
+	return b
+}
+
+func findCoverage(ctx context.Context, project Project) (*coverageResult, error) {
+	if project.CoverageReport != "" {
+		data, err := readCoverageReport(ctx, project, project.CoverageReport)
+		if err != nil {
+			return nil, err
+		}
+		percent, err := parseCoverage(data)
+		if err != nil {
+			return nil, err
+		}
+		link := project.URL
+		if strings.HasPrefix(project.CoverageReport, "http") {
+			link = project.CoverageReport
+		}
+		return &coverageResult{Percent: percent, Link: link}, nil
+	}
+
+	for _, resolve := range coverageResolvers {
+		result, err := resolve(ctx, project)
+		if err != nil {
+			return nil, err
+		}
+		if result != nil {
+			return result, nil
+		}
+	}
+
+	projectPath, err := download(ctx, project)
+	if err != nil {
+		return nil, err
+	}
+	for _, report := range wellKnownCoverageReports {
+		data, err := readCloneFile(projectPath, report)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		percent, err := parseCoverage(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", report, err)
+		}
+		return &coverageResult{Percent: percent, Link: project.URL}, nil
+	}
+	return nil, nil
+}
+
+func readCoverageReport(ctx context.Context, project Project, report string) ([]byte, error) {
+	if strings.HasPrefix(report, "http") {
+		resp, err := httpGet(ctx, "http", report)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, newStatusError("fetching "+report, resp)
+		}
+		return ioutil.ReadAll(resp.Body)
+	}
+
+	projectPath, err := download(ctx, project)
+	if err != nil {
+		return nil, err
+	}
+	return readCloneFile(projectPath, report)
+}
+
+// parseCoverage detects the report format and returns the line coverage in
+// percent. Cobertura XML, LCOV and Go coverage profiles are supported.
+func parseCoverage(data []byte) (float64, error) {
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("<")):
+		return parseCobertura(trimmed)
+	case bytes.HasPrefix(trimmed, []byte("mode:")):
+		return parseGoCover(trimmed)
+	case bytes.Contains(trimmed, []byte("\nLF:")) || bytes.HasPrefix(trimmed, []byte("TN:")) || bytes.HasPrefix(trimmed, []byte("SF:")):
+		return parseLCOV(trimmed)
+	}
+	return 0, errors.New("unknown coverage report format")
+}
+
+func parseCobertura(data []byte) (float64, error) {
+	var report struct {
+		LineRate float64 `xml:"line-rate,attr"`
+	}
+	if err := xml.Unmarshal(data, &report); err != nil {
+		return 0, err
+	}
+	return report.LineRate * 100, nil
+}
+
+func parseLCOV(data []byte) (float64, error) {
+	found, hit := 0, 0
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "LF:"):
+			n, err := strconv.Atoi(line[3:])
+			if err != nil {
+				return 0, err
+			}
+			found += n
+		case strings.HasPrefix(line, "LH:"):
+			n, err := strconv.Atoi(line[3:])
+			if err != nil {
+				return 0, err
+			}
+			hit += n
+		}
+	}
+	if found == 0 {
+		return 0, errors.New("no lines in lcov report")
+	}
+	return float64(hit) / float64(found) * 100, nil
+}
+
+// parseGoCover returns the statement coverage of a Go coverage profile.
+// Blocks listed several times, e.g. with -coverpkg, are counted once.
+func parseGoCover(data []byte) (float64, error) {
+	type block struct {
+		statements int
+		covered    bool
+	}
+	blocks := map[string]*block{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "mode:") || line == "" {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			return 0, fmt.Errorf("invalid cover profile line %q", line)
+		}
+		statements, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return 0, err
+		}
+		count, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return 0, err
+		}
+		b, ok := blocks[fields[0]]
+		if !ok {
+			b = &block{statements: statements}
+			blocks[fields[0]] = b
+		}
+		b.covered = b.covered || count > 0
+	}
+
+	total, covered := 0, 0
+	for _, b := range blocks {
+		total += b.statements
+		if b.covered {
+			covered += b.statements
+		}
+	}
+	if total == 0 {
+		return 0, errors.New("no statements in cover profile")
+	}
+	return float64(covered) / float64(total) * 100, nil
+}
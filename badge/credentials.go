@@ -0,0 +1,184 @@
+package badge
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+)
+
+// TokenSource reads an access token from an environment variable, a file or
+// the output of a command.
+type TokenSource struct {
+	Env     string   `yaml:"env,omitempty"`
+	File    string   `yaml:"file,omitempty"`
+	Command []string `yaml:"command,omitempty"`
+}
+
+// Credential configures the access to a forge host. Type is github or gitlab
+// and only needed for self-hosted instances. BaseURL is the API URL, it
+// defaults to https://<host>/api/v3/ for GitHub Enterprise and
+// https://<host> for GitLab.
+type Credential struct {
+	TokenSource `yaml:",inline"`
+	Type        string `yaml:"type,omitempty"`
+	BaseURL     string `yaml:"base-url,omitempty"`
+}
+
+// Credentials map hosts to their credentials.
+var Credentials = map[string]Credential{}
+
+var defaultTokens = map[string]string{}
+
+// defaultTokenHosts are the hosts that get the default token of a forge.
+var defaultTokenHosts = map[string]string{"github.com": "github", "gitlab.com": "gitlab"}
+
+// SetDefaultToken sets the token of github.com or gitlab.com, used if the
+// host has no configured credentials.
+func SetDefaultToken(forge, token string) {
+	defaultTokens[forge] = token
+}
+
+// Forge returns github or gitlab for projects hosted on these forges and an
+// empty string for all others.
+func Forge(project Project) string {
+	switch {
+	case project.Hoster == "github.com" || Credentials[project.Hoster].Type == "github":
+		return "github"
+	case project.Hoster == "gitlab.com" || project.IsGitlab || Credentials[project.Hoster].Type == "gitlab":
+		return "gitlab"
+	}
+	return ""
+}
+
+// HasCredentials reports whether a token for any host of a forge is set.
+func HasCredentials(forge string) bool {
+	if defaultTokens[forge] != "" {
+		return true
+	}
+	for host, credential := range Credentials {
+		if Forge(Project{Hoster: host}) == forge && !credential.TokenSource.empty() {
+			return true
+		}
+	}
+	return false
+}
+
+func (s TokenSource) empty() bool {
+	return s.Env == "" && s.File == "" && len(s.Command) == 0
+}
+
+var tokenCache sync.Map
+
+func (s TokenSource) read() (string, error) {
+	key := fmt.Sprintf("%#v", s)
+	if token, ok := tokenCache.Load(key); ok {
+		return token.(string), nil
+	}
+
+	var token string
+	switch {
+	case s.Env != "":
+		value, ok := os.LookupEnv(s.Env)
+		if !ok {
+			return "", fmt.Errorf("token environment variable %s is not set", s.Env)
+		}
+		token = value
+	case s.File != "":
+		b, err := ioutil.ReadFile(s.File)
+		if err != nil {
+			return "", fmt.Errorf("token file: %w", err)
+		}
+		token = string(b)
+	case len(s.Command) > 0:
+		b, err := exec.Command(s.Command[0], s.Command[1:]...).Output()
+		if err != nil {
+			return "", fmt.Errorf("token command %s: %w", s.Command[0], err)
+		}
+		token = string(b)
+	}
+
+	token = strings.TrimSpace(token)
+	tokenCache.Store(key, token)
+	return token, nil
+}
+
+// Token returns the access token of a project. The token-from of the project
+// wins over the credentials of its host and the default token of its forge.
+// Default tokens are only sent to github.com and gitlab.com, never to
+// self-hosted instances.
+// Tokens are never stored in a Project, so they cannot end up in dumps of the
+// configuration or in templates.
+func Token(project Project) (string, error) {
+	source := project.TokenFrom
+	if source == nil || source.empty() {
+		credential, ok := Credentials[project.Hoster]
+		if !ok || credential.TokenSource.empty() {
+			return defaultTokens[defaultTokenHosts[project.Hoster]], nil
+		}
+		source = &credential.TokenSource
+	}
+	token, err := source.read()
+	if err != nil {
+		return "", &tokenError{err}
+	}
+	return token, nil
+}
+
+func basicAuth(project Project) (*githttp.BasicAuth, error) {
+	token, err := Token(project)
+	if err != nil {
+		return nil, err
+	}
+	return &githttp.BasicAuth{
+		Username: "xx", // yes, this can be anything except an empty string
+		Password: token,
+	}, nil
+}
+
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e *redactedError) Error() string { return e.msg }
+func (e *redactedError) Unwrap() error { return e.err }
+
+var userinfoRe = regexp.MustCompile(`([a-z][a-z0-9+.\-]*://)[^/\s@]+@`)
+
+// RedactString replaces all known tokens and the credentials of URLs in s.
+func RedactString(s string) string {
+	s = userinfoRe.ReplaceAllString(s, "${1}***@")
+	var tokens []string
+	for _, token := range defaultTokens {
+		tokens = append(tokens, token)
+	}
+	tokenCache.Range(func(_, token interface{}) bool {
+		tokens = append(tokens, token.(string))
+		return true
+	})
+	for _, token := range tokens {
+		if token != "" {
+			s = strings.ReplaceAll(s, token, "***")
+		}
+	}
+	return s
+}
+
+// Redact removes tokens from the message of an error, e.g. from clone errors
+// that echo URLs. The original error can still be unwrapped.
+func Redact(err error) error {
+	if err == nil {
+		return nil
+	}
+	msg := RedactString(err.Error())
+	if msg == err.Error() {
+		return err
+	}
+	return &redactedError{msg: msg, err: err}
+}
@@ -1,19 +1,23 @@
 package badge
 
-import "github.com/narqo/go-badge"
+import (
+	"context"
+	"github.com/narqo/go-badge"
+)
 
 func InitDefaultBadges() {
 	badges["icon"] = icon
-	badges["travis"] = markdownBadge("https://travis-ci.org/{{.Namespace}}/{{.Name}}.svg?branch=master", "https://travis-ci.org/{{.Namespace}}/{{.Name}}", nil)
+	badges["travis"] = markdownBadge("https://travis-ci.org/{{.Namespace}}/{{.Name}}.svg?branch={{.DefaultBranch}}", "https://travis-ci.org/{{.Namespace}}/{{.Name}}", nil)
 	badges["gocover"] = markdownBadge("http://gocover.io/_badge/{{.Hoster}}/{{.Namespace}}/{{.Name}}", "https://gocover.io/{{.Hoster}}/{{.Namespace}}/{{.Name}}", nil)
-	badges["codecov"] = markdownBadge("https://codecov.io/gh/{{.Namespace}}/{{.Name}}/branch/master/graph/badge.svg", "https://codecov.io/gh/{{.Namespace}}/{{.Name}}", nil)
+	badges["codecov"] = markdownBadge("https://codecov.io/gh/{{.Namespace}}/{{.Name}}/branch/{{.DefaultBranch}}/graph/badge.svg", "https://codecov.io/gh/{{.Namespace}}/{{.Name}}", nil)
 	badges["goreportcard"] = markdownBadge("https://goreportcard.com/badge/{{.GoImportPath}}", "https://goreportcard.com/report/{{.GoImportPath}}", nil)
 	badges["godoc"] = markdownBadge("https://godoc.org/{{.GoImportPath}}?status.svg", "https://godoc.org/{{.GoImportPath}}", nil)
 	badges["owner"] = owner
 	badges["criticality"] = criticality
+	setCost(CostClone, "owner")
 }
 
-func icon(project Project) *Badge {
+func icon(ctx context.Context, project Project) *Badge {
 	switch project.Hoster {
 	case "github.com":
 		return &Badge{URL: "style/github.png", Link: project.URL, Title: "icon"}
@@ -24,14 +28,32 @@ func icon(project Project) *Badge {
 	}
 }
 
-func owner(project Project) *Badge {
+// owner is looked up in the meta data, the CODEOWNERS file and the topics of
+// the project, in this order.
+func owner(ctx context.Context, project Project) *Badge {
 	if owner, ok := project.Meta["owner"]; ok {
 		return svgBadge(project.Hoster, project.Name, "owner", "owner", owner, badge.ColorBlue, project.URL, nil)
 	}
+
+	owner, location, err := codeowner(ctx, project)
+	if err != nil {
+		return errorBadge("owner", project, err)
+	}
+	if owner != "" {
+		return svgBadge(project.Hoster, project.Name, "owner", "owner", owner, badge.ColorBlue, fileURL(project, location), nil)
+	}
+
+	owner, err = forgeOwner(ctx, project)
+	if err != nil {
+		return errorBadge("owner", project, err)
+	}
+	if owner != "" {
+		return svgBadge(project.Hoster, project.Name, "owner", "owner", owner, badge.ColorBlue, project.URL, nil)
+	}
 	return svgBadge(project.Hoster, project.Name, "owner", "owner", "unknown", badge.ColorRed, project.URL, nil)
 }
 
-func criticality(project Project) *Badge {
+func criticality(ctx context.Context, project Project) *Badge {
 	criticality := "low"
 	color := badge.ColorBrightgreen
 	if c, ok := project.Meta["criticality"]; ok {
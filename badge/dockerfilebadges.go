@@ -0,0 +1,192 @@
+package badge
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/narqo/go-badge"
+)
+
+func InitDockerfileBadges() {
+	badges["dockerfile"] = dockerfile
+	setCost(CostClone, "dockerfile")
+	setIncremental("dockerfile")
+}
+
+type dockerInstruction struct {
+	Line    int
+	Command string
+	Args    []string
+}
+
+func isDockerfile(name string) bool {
+	return name == "Dockerfile" || strings.HasPrefix(name, "Dockerfile.") || strings.HasSuffix(name, ".Dockerfile")
+}
+
+// parseDockerfile splits a Dockerfile into instructions, joining continued
+// lines and dropping comments.
+func parseDockerfile(content []byte) []dockerInstruction {
+	var instructions []dockerInstruction
+	var current string
+	start := 0
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(text, "#") {
+			continue
+		}
+		if current == "" {
+			start = line
+		}
+		if strings.HasSuffix(text, "\\") {
+			current += strings.TrimSuffix(text, "\\") + " "
+			continue
+		}
+		current += text
+		fields := strings.Fields(current)
+		if len(fields) > 0 {
+			instructions = append(instructions, dockerInstruction{Line: start, Command: strings.ToUpper(fields[0]), Args: fields[1:]})
+		}
+		current = ""
+	}
+	return instructions
+}
+
+// withoutFlags drops flags like --platform or --chown from the arguments.
+func withoutFlags(args []string) []string {
+	var rest []string
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "--") {
+			rest = append(rest, arg)
+		}
+	}
+	return rest
+}
+
+// execForm parses arguments given as a JSON array, like ["src", "dest"].
+// Other arguments are returned unchanged.
+func execForm(args []string) []string {
+	joined := strings.Join(args, " ")
+	if !strings.HasPrefix(joined, "[") {
+		return args
+	}
+	var list []string
+	if err := json.Unmarshal([]byte(joined), &list); err != nil {
+		return args
+	}
+	return list
+}
+
+// lintDockerfile checks a Dockerfile for unpinned base images, remote ADD
+// sources and a final stage running as root or without HEALTHCHECK.
+func lintDockerfile(path string, content []byte) []string {
+	var findings []string
+	stages := map[string]bool{}
+	user, healthcheck, lastFrom := "", false, 0
+
+	for _, instruction := range parseDockerfile(content) {
+		args := withoutFlags(instruction.Args)
+		switch instruction.Command {
+		case "FROM":
+			if len(args) == 0 {
+				continue
+			}
+			image := args[0]
+			if len(args) >= 3 && strings.EqualFold(args[1], "AS") {
+				stages[strings.ToLower(args[2])] = true
+			}
+			user, healthcheck, lastFrom = "", false, instruction.Line
+			if image == "scratch" || stages[strings.ToLower(image)] || strings.Contains(image, "$") || strings.Contains(image, "@sha256:") {
+				continue
+			}
+			if strings.HasSuffix(image, ":latest") || !strings.Contains(image[strings.LastIndex(image, "/")+1:], ":") {
+				findings = append(findings, fmt.Sprintf("%s:%d: base image %s uses the latest tag", path, instruction.Line, image))
+			} else {
+				findings = append(findings, fmt.Sprintf("%s:%d: base image %s is not pinned by digest", path, instruction.Line, image))
+			}
+		case "USER":
+			if len(args) > 0 {
+				user = strings.SplitN(args[0], ":", 2)[0]
+			}
+		case "HEALTHCHECK":
+			healthcheck = len(args) == 0 || !strings.EqualFold(args[0], "NONE")
+		case "ADD":
+			args = execForm(args)
+			if len(args) < 2 {
+				continue
+			}
+			for _, src := range args[:len(args)-1] {
+				if strings.HasPrefix(src, "http://") || strings.HasPrefix(src, "https://") {
+					findings = append(findings, fmt.Sprintf("%s:%d: ADD of remote URL %s", path, instruction.Line, src))
+				}
+			}
+		}
+	}
+
+	if lastFrom == 0 {
+		return findings
+	}
+	if user == "" || user == "root" || user == "0" {
+		findings = append(findings, fmt.Sprintf("%s:%d: final stage runs as root", path, lastFrom))
+	}
+	if !healthcheck {
+		findings = append(findings, fmt.Sprintf("%s:%d: final stage has no HEALTHCHECK", path, lastFrom))
+	}
+	return findings
+}
+
+func dockerfile(ctx context.Context, project Project) *Badge {
+	projectPath, err := download(ctx, project)
+	if err != nil {
+		return errorBadge("dockerfile", project, err)
+	}
+
+	dockerfiles := 0
+	var findings []string
+	err = filepath.Walk(projectPath, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if p != projectPath && vendoredDirs[info.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !isDockerfile(info.Name()) {
+			return nil
+		}
+		content, err := ioutil.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(projectPath, p)
+		dockerfiles++
+		findings = append(findings, lintDockerfile(filepath.ToSlash(rel), content)...)
+		return nil
+	})
+	if err != nil {
+		return errorBadge("dockerfile", project, err)
+	}
+	if dockerfiles == 0 {
+		return nil
+	}
+
+	var b *Badge
+	if len(findings) > 0 {
+		dockerfileLog := filepath.Join("badges", project.Hoster, project.Name, "dockerfile.txt")
+		b = svgBadge(project.Hoster, project.Name, "dockerfile", "dockerfile", fmt.Sprintf("%d issues", len(findings)), badge.ColorOrange, dockerfileLog, nil)
+		_ = writeReport(ctx, dockerfileLog, []byte(strings.Join(findings, "\n")+"\n"))
+	} else {
+		b = svgBadge(project.Hoster, project.Name, "dockerfile", "dockerfile", "valid", badge.ColorBrightgreen, project.URL, nil)
+	}
+	b.Value = len(findings)
+	return b
+}
@@ -1,51 +1,92 @@
 package badge
 
 import (
-	"crypto/tls"
-	"github.com/go-git/go-git/v5/plumbing/transport/client"
+	"context"
+	"fmt"
 	"io/ioutil"
-	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
 	"sync"
-	"time"
 
+	"github.com/enfipy/locker"
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport/client"
 	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
 )
 
-var downloaded = map[string]string{}
-var downloadLock = sync.Mutex{}
+var downloaded sync.Map
+var downloadLocker = locker.Initialize()
+var installOnce sync.Once
 
-func download(project Project) (string, error) {
-	downloadLock.Lock()
-	defer downloadLock.Unlock()
+func installHTTPClient() {
+	installOnce.Do(func() {
+		client.InstallProtocol("https", githttp.NewClient(newHTTPClient("git")))
+	})
+}
 
-	if name, ok := downloaded[project.URL]; ok {
-		return name, nil
-	}
+func download(ctx context.Context, project Project) (string, error) {
+	downloadLocker.Lock(project.URL)
+	defer downloadLocker.Unlock(project.URL)
 
-	name, err := ioutil.TempDir("", "git")
-	if err != nil {
-		return "", err
+	if name, ok := downloaded.Load(project.URL); ok {
+		return name.(string), nil
 	}
 
-	customClient := &http.Client{
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: Insecure},
-			TLSHandshakeTimeout: 10 * time.Second,
-		},
-		Timeout: 10 * time.Second,
-	}
-	client.InstallProtocol("https", githttp.NewClient(customClient))
+	installHTTPClient()
 
-	_, err = git.PlainClone(name, false, &git.CloneOptions{Auth: &githttp.BasicAuth{
-		Username: "xx", // yes, this can be anything except an empty string
-		Password: project.Token,
-	}, URL: project.URL})
+	auth, err := basicAuth(project)
 	if err != nil {
 		return "", err
+	}
+	options := &git.CloneOptions{Auth: auth, URL: project.URL}
+	if project.DefaultBranch != "" {
+		options.ReferenceName = plumbing.NewBranchReferenceName(project.DefaultBranch)
+	}
 
+	// The transport retries single requests, a clone interrupted while
+	// streaming the pack is started again from scratch.
+	policy := retryPolicy("git")
+	var name string
+	for attempt := 1; ; attempt++ {
+		name, err = ioutil.TempDir("", "git")
+		if err != nil {
+			return "", err
+		}
+		_, err = git.PlainCloneContext(ctx, name, false, options)
+		if err == nil {
+			break
+		}
+		os.RemoveAll(name)
+		if attempt >= policy.Attempts || ctx.Err() != nil || !transientCloneError(err) {
+			return "", &cloneError{err}
+		}
+		if err := sleep(ctx, policy.backoff(attempt)); err != nil {
+			return "", err
+		}
+		countRetry(ctx)
 	}
 
-	downloaded[project.URL] = name
+	downloaded.Store(project.URL, name)
 	return name, nil
 }
+
+// readCloneFile reads a file of a clone. Symbolic links are only followed
+// within the clone, so a repository cannot make the dashboard read and
+// publish other files.
+func readCloneFile(projectPath, name string) ([]byte, error) {
+	root, err := filepath.EvalSymlinks(projectPath)
+	if err != nil {
+		return nil, err
+	}
+	p, err := filepath.EvalSymlinks(filepath.Join(root, filepath.FromSlash(name)))
+	if err != nil {
+		return nil, err
+	}
+	rel, err := filepath.Rel(root, p)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil, fmt.Errorf("%s points outside of the repository", name)
+	}
+	return ioutil.ReadFile(p)
+}
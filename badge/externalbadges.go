@@ -3,7 +3,9 @@ package badge
 import (
 	"bytes"
 	"context"
-	"io/ioutil"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -14,79 +16,157 @@ import (
 )
 
 func InitExternalCommandBadges() {
-	badges["pycodestyle"] = pycodestyle
+	badges["pycodestyle"] = commandBadge(Command{Name: "pycodestyle", Command: []string{"pycodestyle"}, Link: "https://pypi.org/project/pycodestyle/"})
 	badges["superlint"] = superlint
 	badges["bandit"] = bandit
-	badges["shhgit"] = shhgit
+	badges["secrets"] = secrets
+	badges["shhgit"] = secrets
+	setCost(CostClone, "secrets", "shhgit")
+	setCost(CostCommand, "pycodestyle", "superlint", "bandit")
+	setIncremental("secrets", "shhgit", "pycodestyle", "superlint", "bandit")
+	setSettings(func() interface{} { return []interface{}{secretRules, SecretLocations} }, "secrets", "shhgit")
 }
 
-func shhgit(project Project) *Badge {
-	projectPath, err := download(project)
-	if err != nil {
-		return errorBadge("shhgit", project, err)
+// Command is a user defined badge that runs an external command with the
+// path of the cloned project as last argument. A non-zero exit code marks the
+// project as invalid.
+type Command struct {
+	Name    string   `yaml:"name,omitempty"`
+	Command []string `yaml:"command,omitempty"`
+	Link    string   `yaml:"link,omitempty"`
+}
+
+func InitCommandBadges(commands []Command) {
+	for _, command := range commands {
+		badges[command.Name] = commandBadge(command)
+		setCost(CostCommand, command.Name)
+		setIncremental(command.Name)
+		command := command
+		setSettings(func() interface{} { return command }, command.Name)
 	}
+}
 
-	cmd := exec.Command("/root/go/bin/shhgit", "--config-path", "/shhgit", "--local", projectPath)
-	var out, errb bytes.Buffer
-	cmd.Stdout = &out
-	cmd.Stderr = &errb
-	err = cmd.Run()
-	if err != nil {
-		shhgitLog := filepath.Join("badges", project.Hoster, project.Name, "shhgit.txt")
-		b := svgBadge(project.Hoster, project.Name, "shhgit", "shhgit", "invalid", badge.ColorRed, shhgitLog, nil)
-		_ = ioutil.WriteFile(shhgitLog, out.Bytes(), 0666)
-		return b
+func commandBadge(command Command) badgeCreation {
+	return func(ctx context.Context, project Project) *Badge {
+		if len(command.Command) == 0 {
+			return errorBadge(command.Name, project, errors.New("no command defined"))
+		}
+
+		projectPath, err := download(ctx, project)
+		if err != nil {
+			return errorBadge(command.Name, project, err)
+		}
+
+		args := append(append([]string{}, command.Command[1:]...), projectPath)
+		cmd := exec.CommandContext(ctx, command.Command[0], args...)
+		var out, errb bytes.Buffer
+		cmd.Stdout = &out
+		cmd.Stderr = &errb
+		err = cmd.Run()
+		if ctx.Err() != nil {
+			return errorBadge(command.Name, project, ctx.Err())
+		}
+		if missingTool(err) {
+			return errorBadge(command.Name, project, err)
+		}
+
+		_, _ = writeSarif(ctx, project, command.Name, newSarifLog(command.Name, command.Link, lineResults(out.Bytes(), projectPath, "warning")))
+		if err != nil {
+			commandLog := filepath.Join("badges", project.Hoster, project.Name, command.Name+".txt")
+			b := svgBadge(project.Hoster, project.Name, command.Name, command.Name, "invalid", badge.ColorRed, commandLog, nil)
+			_ = writeReport(ctx, commandLog, out.Bytes())
+			return b
+		}
+		return svgBadge(project.Hoster, project.Name, command.Name, command.Name, "valid", badge.ColorBrightgreen, command.Link, nil)
 	}
-	return svgBadge(project.Hoster, project.Name, "shhgit", "shhgit", "valid", badge.ColorBrightgreen, "https://github.com/eth0izzle/shhgit", nil)
 }
 
-func bandit(project Project) *Badge {
-	projectPath, err := download(project)
-	if err != nil {
-		return errorBadge("bandit", project, err)
+// banditReport is the part of the bandit JSON report that is converted to SARIF.
+type banditReport struct {
+	Results []struct {
+		Filename      string `json:"filename"`
+		LineNumber    int    `json:"line_number"`
+		ColOffset     int    `json:"col_offset"`
+		IssueSeverity string `json:"issue_severity"`
+		IssueText     string `json:"issue_text"`
+		TestID        string `json:"test_id"`
+	} `json:"results"`
+}
+
+// text lists the issues like bandit does in its screen format, one per line.
+func (r *banditReport) text(projectPath string) []byte {
+	var text bytes.Buffer
+	for _, result := range r.Results {
+		file := result.Filename
+		if rel, err := filepath.Rel(projectPath, file); err == nil {
+			file = rel
+		}
+		fmt.Fprintf(&text, "%s:%d: [%s:%s] %s\n", filepath.ToSlash(file), result.LineNumber, result.TestID, result.IssueSeverity, result.IssueText)
 	}
+	return text.Bytes()
+}
 
-	cmd := exec.Command("bandit", "-r", projectPath)
-	var out, errb bytes.Buffer
-	cmd.Stdout = &out
-	cmd.Stderr = &errb
-	err = cmd.Run()
-	if err != nil {
-		banditLog := filepath.Join("badges", project.Hoster, project.Name, "bandit.txt")
-		b := svgBadge(project.Hoster, project.Name, "bandit", "bandit", "invalid", badge.ColorRed, banditLog, nil)
-		_ = ioutil.WriteFile(banditLog, out.Bytes(), 0666)
-		return b
+func (r *banditReport) sarifResults(projectPath string) []sarifResult {
+	var results []sarifResult
+	for _, result := range r.Results {
+		level := "note"
+		switch result.IssueSeverity {
+		case "HIGH":
+			level = "error"
+		case "MEDIUM":
+			level = "warning"
+		}
+		file := result.Filename
+		if rel, err := filepath.Rel(projectPath, file); err == nil {
+			file = rel
+		}
+		results = append(results, newSarifResult(result.TestID, level, result.IssueText, file, result.LineNumber, result.ColOffset+1))
 	}
-	return svgBadge(project.Hoster, project.Name, "bandit", "bandit", "valid", badge.ColorBrightgreen, "https://pypi.org/project/bandit/", nil)
+	return results
 }
 
-func pycodestyle(project Project) *Badge {
-	projectPath, err := download(project)
+func bandit(ctx context.Context, project Project) *Badge {
+	projectPath, err := download(ctx, project)
 	if err != nil {
-		return errorBadge("pycodestyle", project, err)
+		return errorBadge("bandit", project, err)
 	}
 
-	cmd := exec.Command("pycodestyle", projectPath)
+	cmd := exec.CommandContext(ctx, "bandit", "-r", "-f", "json", projectPath)
 	var out, errb bytes.Buffer
 	cmd.Stdout = &out
 	cmd.Stderr = &errb
 	err = cmd.Run()
+	if ctx.Err() != nil {
+		return errorBadge("bandit", project, ctx.Err())
+	}
+	if missingTool(err) {
+		return errorBadge("bandit", project, err)
+	}
+
+	// The JSON report is converted for the linked log, which shows the
+	// output of bandit if it is no report.
+	text := append(out.Bytes(), errb.Bytes()...)
+	var report banditReport
+	if jsonErr := json.Unmarshal(out.Bytes(), &report); jsonErr == nil {
+		_, _ = writeSarif(ctx, project, "bandit", newSarifLog("bandit", "https://pypi.org/project/bandit/", report.sarifResults(projectPath)))
+		text = report.text(projectPath)
+	}
 	if err != nil {
-		pycodestyleLog := filepath.Join("badges", project.Hoster, project.Name, "pycodestyle.txt")
-		b := svgBadge(project.Hoster, project.Name, "pycodestyle", "pycodestyle", "invalid", badge.ColorRed, pycodestyleLog, nil)
-		_ = ioutil.WriteFile(pycodestyleLog, out.Bytes(), 0666)
+		banditLog := filepath.Join("badges", project.Hoster, project.Name, "bandit.txt")
+		b := svgBadge(project.Hoster, project.Name, "bandit", "bandit", "invalid", badge.ColorRed, banditLog, nil)
+		_ = writeReport(ctx, banditLog, text)
 		return b
 	}
-	return svgBadge(project.Hoster, project.Name, "pycodestyle", "pycodestyle", "valid", badge.ColorBrightgreen, "https://pypi.org/project/pycodestyle/", nil)
+	return svgBadge(project.Hoster, project.Name, "bandit", "bandit", "valid", badge.ColorBrightgreen, "https://pypi.org/project/bandit/", nil)
 }
 
-func superlint(project Project) *Badge {
-	projectPath, err := download(project)
+func superlint(ctx context.Context, project Project) *Badge {
+	projectPath, err := download(ctx, project)
 	if err != nil {
 		return errorBadge("superlint", project, err)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
 	defer cancel() // The cancel should be deferred so resources are cleaned up
 	cmd := exec.CommandContext(ctx, "/action/lib/linter.sh")
 	cmd.Env = append(os.Environ(),
@@ -100,12 +180,18 @@ func superlint(project Project) *Badge {
 	var report bytes.Buffer
 	cmd.Stderr = &report
 	err = cmd.Run()
+	if ctx.Err() != nil {
+		return errorBadge("superlint", project, ctx.Err())
+	}
+	if missingTool(err) {
+		return errorBadge("superlint", project, err)
+	}
 
-	_ = os.MkdirAll(filepath.Join("badges", project.Hoster, project.Name), 0777)
 	reportData := ansiRe.ReplaceAll(report.Bytes(), []byte{})
 	reportData = logRe.ReplaceAll(reportData, []byte{})
 	lintLog := filepath.Join("badges", project.Hoster, project.Name, "super-linter.txt")
-	_ = ioutil.WriteFile(lintLog, reportData, 0666)
+	_ = writeReport(ctx, lintLog, reportData)
+	_, _ = writeSarif(ctx, project, "super-linter", newSarifLog("super-linter", "https://github.com/github/super-linter", lineResults(reportData, projectPath, "error")))
 
 	if err != nil {
 		return svgBadge(project.Hoster, project.Name, "super-linter", "super-linter", "invalid", badge.ColorRed, lintLog, nil)
@@ -4,6 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
 	"sync"
 	"time"
 
@@ -15,24 +18,40 @@ import (
 	"golang.org/x/oauth2"
 )
 
-var isGitHub = func(p Project) bool { return p.Hoster == "github.com" }
+var isGitHub = func(p Project) bool { return Forge(p) == "github" }
 
-func InitGitHubBadges(githubAccessToken string) {
-	githubProject := NewGithubProject(githubAccessToken)
+// isGitHubCom matches the projects on github.com, the only host known to
+// shields.io and sloc.xyz.
+var isGitHubCom = func(p Project) bool { return p.Hoster == "github.com" }
+
+func InitGitHubBadges() {
+	githubProject := NewGithubProject()
+	branchResolvers = append(branchResolvers, githubProject.defaultBranch)
+	releaseResolvers = append(releaseResolvers, githubProject.releases)
+	topicResolvers = append(topicResolvers, githubProject.topics)
+	propertyResolvers = append(propertyResolvers, githubProject.properties)
+	updatedResolvers = append(updatedResolvers, githubProject.updated)
+	fileResolvers = append(fileResolvers, githubProject.file)
 	badges["github-branches"] = githubProject.branches
 	badges["github-forks"] = githubProject.forks
 	badges["github-issues"] = githubProject.issues
-	badges["github-lastcommit"] = markdownBadge("https://img.shields.io/github/last-commit/{{.Namespace}}/{{.Name}}", "{{.URL}}", isGitHub)
+	badges["github-lastcommit"] = markdownBadge("https://img.shields.io/github/last-commit/{{.Namespace}}/{{.Name}}", "{{.URL}}", isGitHubCom)
 	badges["github-license"] = githubProject.license
 	badges["github-newcommits"] = githubProject.commitssince
-	badges["github-pipeline"] = markdownBadge("{{.URL}}/workflows/{{.Workflow}}/badge.svg", "{{.URL}}/actions", isGitHub)
+	badges["github-pipeline"] = githubProject.pipeline
 	badges["github-pullrequests"] = githubProject.pullRequests
+	badges["github-compliance"] = githubProject.compliance
+	badges["github-oldest-issue"] = githubProject.oldestIssue
+	badges["github-pr-age"] = githubProject.pullRequestAge
+	badges["github-pr-waiting"] = githubProject.reviewWait
+	badges["github-untriaged"] = githubProject.untriaged
 	badges["github-size"] = githubProject.size
 	badges["github-stars"] = githubProject.stars
 	badges["github-version"] = githubProject.tag
 	badges["github-visibility"] = githubProject.visibility
 	badges["github-watchers"] = githubProject.watchers
-	badges["github-sloc"] = markdownBadge("https://sloc.xyz/github/{{.Namespace}}/{{.Name}}/", "{{.URL}}", isGitHub)
+	setCost(CostClone, "github-newcommits")
+	badges["github-sloc"] = markdownBadge("https://sloc.xyz/github/{{.Namespace}}/{{.Name}}/", "{{.URL}}", isGitHubCom)
 	// "github-forks":        markdownBadge("https://img.shields.io/github/forks/{{.Namespace}}/{{.Name}}?label=Fork", "{{.URL}}/network", isGitHub),
 	// "github-issues":       markdownBadge("https://img.shields.io/github/issues/{{.Namespace}}/{{.Name}}", "{{.URL}}/issues", isGitHub),
 	badges["github-lastcommit"] = githubProject.lastcommit
@@ -45,31 +64,83 @@ func InitGitHubBadges(githubAccessToken string) {
 }
 
 type GithubProject struct {
-	client                *github.Client
+	clients               map[string]*github.Client
+	clientLock            sync.Mutex
 	locker                *locker.Locker
 	repositoryCache       sync.Map
 	pullrequestCountCache sync.Map
+	ticketCache           sync.Map
 }
 
-func NewGithubProject(githubAccessToken string) *GithubProject {
+func NewGithubProject() *GithubProject {
 	return &GithubProject{
-		client: github.NewClient(
-			oauth2.NewClient(
-				context.Background(),
-				oauth2.StaticTokenSource(
-					&oauth2.Token{AccessToken: githubAccessToken},
-				),
-			),
-		),
-		locker: locker.Initialize(),
+		clients: map[string]*github.Client{},
+		locker:  locker.Initialize(),
 	}
 }
 
-func (b *GithubProject) getProject(project Project) (*github.Repository, *Badge) {
+// getClient returns a client for the host of a project with the token of the
+// project. Hosts other than github.com are GitHub Enterprise servers.
+func (b *GithubProject) getClient(project Project) (*github.Client, error) {
+	token, err := Token(project)
+	if err != nil {
+		return nil, err
+	}
+
+	b.clientLock.Lock()
+	defer b.clientLock.Unlock()
+
+	key := project.Hoster + "\n" + token
+	if client, ok := b.clients[key]; ok {
+		return client, nil
+	}
+
+	httpClient := newHTTPClient("github")
+	if token != "" {
+		ctx := context.WithValue(context.Background(), oauth2.HTTPClient, httpClient)
+		httpClient = oauth2.NewClient(ctx, oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token}))
+	}
+	client := github.NewClient(httpClient)
+	if project.Hoster != "github.com" {
+		baseURL, uploadURL := githubEnterpriseURLs(project.Hoster)
+		client, err = github.NewEnterpriseClient(baseURL, uploadURL, httpClient)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	b.clients[key] = client
+	return client, nil
+}
+
+// githubEnterpriseURLs returns the API and upload URLs of a GitHub Enterprise
+// server. Both are derived from the API URL of the credential of the host,
+// uploads are served next to the API under api/uploads/.
+func githubEnterpriseURLs(host string) (string, string) {
+	baseURL := Credentials[host].BaseURL
+	if baseURL == "" {
+		baseURL = "https://" + host + "/api/v3/"
+	}
+	if !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+	root := strings.TrimSuffix(baseURL, "api/v3/")
+	return root + "api/v3/", root + "api/uploads/"
+}
+
+func (b *GithubProject) getProject(ctx context.Context, project Project) (*github.Repository, *Badge) {
 	if !isGitHub(project) {
 		return nil, svgBadge(project.Hoster, project.Name, "github", "github", "Not a GitHub project", badge.ColorLightgrey, project.URL, errors.New("not a GitHub project"))
 	}
 
+	githubProject, err := b.repository(ctx, project)
+	if err != nil {
+		return nil, svgBadge(project.Hoster, project.Name, "github", "github", "Error", badge.ColorLightgrey, project.URL, err)
+	}
+	return githubProject, nil
+}
+
+func (b *GithubProject) repository(ctx context.Context, project Project) (*github.Repository, error) {
 	b.locker.Lock("repo" + project.URL)
 	defer b.locker.Unlock("repo" + project.URL)
 
@@ -77,16 +148,130 @@ func (b *GithubProject) getProject(project Project) (*github.Repository, *Badge)
 	if ok {
 		return loadedGitHubProject.(*github.Repository), nil
 	}
-	githubProject, _, err := b.client.Repositories.Get(context.Background(), project.Namespace, project.Name)
+	client, err := b.getClient(project)
 	if err != nil {
-		return nil, svgBadge(project.Hoster, project.Name, "github", "github", "Error", badge.ColorLightgrey, project.URL, err)
+		return nil, err
+	}
+	githubProject, _, err := client.Repositories.Get(ctx, project.Namespace, project.Name)
+	if err != nil {
+		return nil, err
 	}
 
 	b.repositoryCache.Store(project.URL, githubProject)
 	return githubProject, nil
 }
 
-func (b *GithubProject) pullRequestCount(project Project) (int, error) {
+func (b *GithubProject) defaultBranch(ctx context.Context, project Project) (string, error) {
+	if !isGitHub(project) {
+		return "", nil
+	}
+
+	githubProject, err := b.repository(ctx, project)
+	if err != nil {
+		return "", err
+	}
+	return githubProject.GetDefaultBranch(), nil
+}
+
+func (b *GithubProject) updated(ctx context.Context, project Project) (time.Time, error) {
+	if !isGitHub(project) {
+		return time.Time{}, nil
+	}
+
+	githubProject, err := b.repository(ctx, project)
+	if err != nil {
+		return time.Time{}, err
+	}
+	// The update time of a repository does not change on pushes.
+	if pushed := githubProject.GetPushedAt().Time; pushed.After(githubProject.GetUpdatedAt().Time) {
+		return pushed, nil
+	}
+	return githubProject.GetUpdatedAt().Time, nil
+}
+
+func (b *GithubProject) file(ctx context.Context, project Project, name string) ([]byte, bool, error) {
+	if !isGitHub(project) {
+		return nil, false, nil
+	}
+
+	client, err := b.getClient(project)
+	if err != nil {
+		return nil, true, err
+	}
+	options := &github.RepositoryContentGetOptions{Ref: project.DefaultBranch}
+	file, _, response, err := client.Repositories.GetContents(ctx, project.Namespace, project.Name, name, options)
+	if isNotFound(response) {
+		return nil, true, nil
+	}
+	if err != nil {
+		return nil, true, err
+	}
+	if file == nil {
+		return nil, true, fmt.Errorf("%s is not a file", name)
+	}
+	content, err := file.GetContent()
+	if err != nil {
+		return nil, true, err
+	}
+	return []byte(content), true, nil
+}
+
+// properties lists the custom properties of a repository. Multi-select
+// values are joined by commas. Servers without custom properties have none.
+func (b *GithubProject) properties(ctx context.Context, project Project) (map[string]string, error) {
+	if !isGitHub(project) {
+		return nil, nil
+	}
+
+	client, err := b.getClient(project)
+	if err != nil {
+		return nil, err
+	}
+	req, err := client.NewRequest(http.MethodGet, fmt.Sprintf("repos/%s/%s/properties/values", project.Namespace, project.Name), nil)
+	if err != nil {
+		return nil, err
+	}
+	var values []struct {
+		PropertyName string      `json:"property_name"`
+		Value        interface{} `json:"value"`
+	}
+	response, err := client.Do(ctx, req, &values)
+	if isNotFound(response) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	properties := map[string]string{}
+	for _, value := range values {
+		switch v := value.Value.(type) {
+		case string:
+			properties[value.PropertyName] = v
+		case []interface{}:
+			var list []string
+			for _, item := range v {
+				list = append(list, fmt.Sprint(item))
+			}
+			properties[value.PropertyName] = strings.Join(list, ",")
+		}
+	}
+	return properties, nil
+}
+
+func (b *GithubProject) topics(ctx context.Context, project Project) ([]string, error) {
+	if !isGitHub(project) {
+		return nil, nil
+	}
+
+	githubProject, err := b.repository(ctx, project)
+	if err != nil {
+		return nil, err
+	}
+	return githubProject.Topics, nil
+}
+
+func (b *GithubProject) pullRequestCount(ctx context.Context, project Project) (int, error) {
 	b.locker.Lock("pr" + project.URL)
 	defer b.locker.Unlock("pr" + project.URL)
 
@@ -96,7 +281,11 @@ func (b *GithubProject) pullRequestCount(project Project) (int, error) {
 			ListOptions: github.ListOptions{PerPage: 1},
 		}
 
-		pullRequests, response, err := b.client.PullRequests.List(context.Background(), project.Namespace, project.Name, opt)
+		client, err := b.getClient(project)
+		if err != nil {
+			return 0, err
+		}
+		pullRequests, response, err := client.PullRequests.List(ctx, project.Namespace, project.Name, opt)
 		if err != nil {
 			return 0, err
 		}
@@ -110,12 +299,12 @@ func (b *GithubProject) pullRequestCount(project Project) (int, error) {
 	return count.(int), nil
 }
 
-func (b *GithubProject) pullRequests(project Project) *Badge {
+func (b *GithubProject) pullRequests(ctx context.Context, project Project) *Badge {
 	if !isGitHub(project) {
 		return nil
 	}
 
-	count, err := b.pullRequestCount(project)
+	count, err := b.pullRequestCount(ctx, project)
 	if err != nil {
 		return svgBadge(project.Hoster, project.Name, "pullrequests", "pull requests", "Error", badge.ColorRed, project.URL+"/pulls", err)
 	}
@@ -124,15 +313,21 @@ func (b *GithubProject) pullRequests(project Project) *Badge {
 	if count > 0 {
 		color = badge.ColorYellow
 	}
-	return svgBadge(project.Hoster, project.Name, "pullrequests", "pull requests", fmt.Sprintf("%d", count), color, project.URL+"/pulls", nil)
+	o := svgBadge(project.Hoster, project.Name, "pullrequests", "pull requests", fmt.Sprintf("%d", count), color, project.URL+"/pulls", nil)
+	o.Value = count
+	return o
 }
 
-func (b *GithubProject) branches(project Project) *Badge {
+func (b *GithubProject) branches(ctx context.Context, project Project) *Badge {
 	if !isGitHub(project) {
 		return nil
 	}
 
-	_, response, err := b.client.Repositories.ListBranches(context.Background(), project.Namespace, project.Name, &github.ListOptions{PerPage: 1})
+	client, err := b.getClient(project)
+	if err != nil {
+		return svgBadge(project.Hoster, project.Name, "branches", "branches", "Error", badge.ColorLightgrey, project.URL+"/branches", err)
+	}
+	_, response, err := client.Repositories.ListBranches(ctx, project.Namespace, project.Name, &github.ListOptions{PerPage: 1})
 	if err != nil {
 		return svgBadge(project.Hoster, project.Name, "branches", "branches", "Error", badge.ColorLightgrey, project.URL+"/branches", err)
 	}
@@ -149,45 +344,288 @@ func (b *GithubProject) branches(project Project) *Badge {
 	default:
 		color = badge.ColorYellow
 	}
-	return svgBadge(project.Hoster, project.Name, "branches", "branches", fmt.Sprintf("%d", branchesCount), color, project.URL+"/branches", nil)
+	o := svgBadge(project.Hoster, project.Name, "branches", "branches", fmt.Sprintf("%d", branchesCount), color, project.URL+"/branches", nil)
+	o.Value = branchesCount
+	return o
 }
 
-func (b *GithubProject) tag(project Project) *Badge {
+func (b *GithubProject) tag(ctx context.Context, project Project) *Badge {
 	if !isGitHub(project) {
 		return nil
 	}
+	return version(ctx, project)
+}
 
-	tags, _, err := b.client.Repositories.ListTags(context.Background(), project.Namespace, project.Name, &github.ListOptions{PerPage: 1})
+func (b *GithubProject) commitssince(ctx context.Context, project Project) *Badge {
+	if !isGitHub(project) {
+		return nil
+	}
+	return unreleased(ctx, project)
+}
+
+func (b *GithubProject) releases(ctx context.Context, project Project) ([]release, error) {
+	if !isGitHub(project) {
+		return nil, nil
+	}
+
+	client, err := b.getClient(project)
 	if err != nil {
-		return svgBadge(project.Hoster, project.Name, "tag", "tag", "Error", badge.ColorLightgrey, project.URL+"/releases", err)
+		return nil, err
+	}
+
+	var releases []release
+	opt := &github.ListOptions{PerPage: 100}
+	for {
+		githubReleases, response, err := client.Repositories.ListReleases(ctx, project.Namespace, project.Name, opt)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range githubReleases {
+			if r.GetDraft() {
+				continue
+			}
+			releases = append(releases, release{Tag: r.GetTagName(), Date: r.GetPublishedAt().Time, Published: true})
+		}
+		if response.NextPage == 0 {
+			return releases, nil
+		}
+		opt.Page = response.NextPage
 	}
-	if len(tags) == 0 {
+}
+
+type workflowRuns struct {
+	WorkflowRuns []struct {
+		Name       string    `json:"name"`
+		Path       string    `json:"path"`
+		Status     string    `json:"status"`
+		Conclusion string    `json:"conclusion"`
+		HTMLURL    string    `json:"html_url"`
+		CreatedAt  time.Time `json:"created_at"`
+		UpdatedAt  time.Time `json:"updated_at"`
+	} `json:"workflow_runs"`
+}
+
+// pipeline shows the state of the latest GitHub Actions run on the default
+// branch. If a workflow is configured, only runs of this workflow are used.
+func (b *GithubProject) pipeline(ctx context.Context, project Project) *Badge {
+	if !isGitHub(project) {
 		return nil
 	}
-	return svgBadge(project.Hoster, project.Name, "tag", "tag", *(tags[0]).Name, badge.ColorBlue, project.URL+"/releases", nil)
+
+	u := fmt.Sprintf("repos/%s/%s/actions/runs?branch=%s&per_page=20", project.Namespace, project.Name, url.QueryEscape(project.DefaultBranch))
+	client, err := b.getClient(project)
+	if err != nil {
+		return errorBadge("pipeline", project, err)
+	}
+	req, err := client.NewRequest("GET", u, nil)
+	if err != nil {
+		return errorBadge("pipeline", project, err)
+	}
+	var runs workflowRuns
+	_, err = client.Do(ctx, req, &runs)
+	if err != nil {
+		return errorBadge("pipeline", project, err)
+	}
+
+	for _, run := range runs.WorkflowRuns {
+		if project.Workflow != "" && run.Name != project.Workflow && !strings.HasSuffix(run.Path, project.Workflow) {
+			continue
+		}
+
+		pipeline := &Pipeline{State: PipelineUnknown, Duration: run.UpdatedAt.Sub(run.CreatedAt), URL: run.HTMLURL}
+		switch {
+		case run.Status == "queued":
+			pipeline.State = PipelinePending
+			pipeline.Duration = 0
+		case run.Status != "completed":
+			pipeline.State = PipelineRunning
+			pipeline.Duration = time.Since(run.CreatedAt)
+		case run.Conclusion == "success":
+			pipeline.State = PipelinePassed
+		case run.Conclusion == "failure" || run.Conclusion == "timed_out":
+			pipeline.State = PipelineFailed
+		case run.Conclusion == "cancelled":
+			pipeline.State = PipelineCanceled
+		}
+		return pipelineBadge("pipeline", project, pipeline)
+	}
+	return nil
+}
+
+// tickets lists all open issues and pull requests. Reviews are only looked up
+// for pull requests older than the review wait threshold.
+func (b *GithubProject) tickets(ctx context.Context, project Project) ([]ticket, error) {
+	b.locker.Lock("tickets" + project.URL)
+	defer b.locker.Unlock("tickets" + project.URL)
+
+	if tickets, ok := b.ticketCache.Load(project.URL); ok {
+		return tickets.([]ticket), nil
+	}
+
+	client, err := b.getClient(project)
+	if err != nil {
+		return nil, err
+	}
+
+	var tickets []ticket
+	opt := &github.IssueListByRepoOptions{State: "open", ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		issues, response, err := client.Issues.ListByRepo(ctx, project.Namespace, project.Name, opt)
+		if err != nil {
+			return nil, err
+		}
+		for _, issue := range issues {
+			t := ticket{
+				Created:     issue.GetCreatedAt(),
+				PullRequest: issue.IsPullRequest(),
+				Labeled:     len(issue.Labels) > 0,
+				Assigned:    len(issue.Assignees) > 0,
+			}
+			if t.PullRequest && ageInDays(t.Created) > Ageing.ReviewWait {
+				reviews, _, err := client.PullRequests.ListReviews(ctx, project.Namespace, project.Name, issue.GetNumber(), &github.ListOptions{PerPage: 1})
+				if err != nil {
+					return nil, err
+				}
+				t.Reviewed = len(reviews) > 0
+			}
+			tickets = append(tickets, t)
+		}
+		if response.NextPage == 0 {
+			break
+		}
+		opt.Page = response.NextPage
+	}
+
+	b.ticketCache.Store(project.URL, tickets)
+	return tickets, nil
 }
 
-func (b *GithubProject) commitssince(project Project) *Badge {
+func (b *GithubProject) oldestIssue(ctx context.Context, project Project) *Badge {
 	if !isGitHub(project) {
 		return nil
 	}
 
-	tags, _, err := b.client.Repositories.ListTags(context.Background(), project.Namespace, project.Name, &github.ListOptions{PerPage: 1})
+	tickets, err := b.tickets(ctx, project)
 	if err != nil {
-		return svgBadge(project.Hoster, project.Name, "tag", "tag", "Error", badge.ColorLightgrey, project.URL+"/releases", nil)
+		return errorBadge("oldest-issue", project, err)
 	}
-	if len(tags) == 0 {
+	return oldestIssueBadge(project, project.URL+"/issues?q=is%3Aissue+is%3Aopen+sort%3Acreated-asc", tickets)
+}
+
+func (b *GithubProject) pullRequestAge(ctx context.Context, project Project) *Badge {
+	if !isGitHub(project) {
 		return nil
 	}
-	return markdownBadge("https://img.shields.io/github/commits-since/{{.Namespace}}/{{.Name}}/latest", "{{.URL}}", isGitHub)(project)
+
+	tickets, err := b.tickets(ctx, project)
+	if err != nil {
+		return errorBadge("pr-age", project, err)
+	}
+	return pullRequestAgeBadge(project, "pr age", project.URL+"/pulls", tickets)
+}
+
+func (b *GithubProject) reviewWait(ctx context.Context, project Project) *Badge {
+	if !isGitHub(project) {
+		return nil
+	}
+
+	tickets, err := b.tickets(ctx, project)
+	if err != nil {
+		return errorBadge("review-wait", project, err)
+	}
+	return reviewWaitBadge(project, "prs awaiting review", project.URL+"/pulls?q=is%3Apr+is%3Aopen+review%3Anone", tickets)
+}
+
+func (b *GithubProject) untriaged(ctx context.Context, project Project) *Badge {
+	if !isGitHub(project) {
+		return nil
+	}
+
+	tickets, err := b.tickets(ctx, project)
+	if err != nil {
+		return errorBadge("untriaged", project, err)
+	}
+	return untriagedBadge(project, project.URL+"/issues?q=is%3Aissue+is%3Aopen+no%3Alabel+no%3Aassignee", tickets)
+}
+
+func isNotFound(response *github.Response) bool {
+	return response != nil && response.StatusCode == http.StatusNotFound
+}
+
+// compliance audits the protection of the default branch and the security
+// settings of the repository.
+func (b *GithubProject) compliance(ctx context.Context, project Project) *Badge {
+	if !isGitHub(project) {
+		return nil
+	}
+
+	client, err := b.getClient(project)
+	if err != nil {
+		return errorBadge("compliance", project, err)
+	}
+
+	branch := project.DefaultBranch
+	protection, response, err := client.Repositories.GetBranchProtection(ctx, project.Namespace, project.Name, branch)
+	if err != nil && !isNotFound(response) {
+		return errorBadge("compliance", project, err)
+	}
+	if protection == nil {
+		protection = &github.Protection{}
+	}
+
+	checks := []complianceCheck{{Name: "branch protection on " + branch, Passed: err == nil}}
+
+	reviews := 0
+	if protection.RequiredPullRequestReviews != nil {
+		reviews = protection.RequiredPullRequestReviews.RequiredApprovingReviewCount
+	}
+	checks = append(checks, complianceCheck{Name: "required reviews", Passed: reviews > 0, Detail: fmt.Sprintf("%d reviews", reviews)})
+
+	var statusChecks []string
+	if protection.RequiredStatusChecks != nil {
+		statusChecks = protection.RequiredStatusChecks.Contexts
+	}
+	checks = append(checks, complianceCheck{Name: "required status checks", Passed: len(statusChecks) > 0, Detail: strings.Join(statusChecks, ", ")})
+	checks = append(checks, complianceCheck{Name: "enforced for admins", Passed: protection.EnforceAdmins != nil && protection.EnforceAdmins.Enabled})
+
+	signatures := false
+	if err == nil {
+		req, err := client.NewRequest("GET", fmt.Sprintf("repos/%s/%s/branches/%s/protection/required_signatures", project.Namespace, project.Name, url.PathEscape(branch)), nil)
+		if err != nil {
+			return errorBadge("compliance", project, err)
+		}
+		req.Header.Set("Accept", "application/vnd.github.zzzax-preview+json")
+		var requiredSignatures struct {
+			Enabled bool `json:"enabled"`
+		}
+		response, err = client.Do(ctx, req, &requiredSignatures)
+		if err != nil && !isNotFound(response) {
+			return errorBadge("compliance", project, err)
+		}
+		signatures = requiredSignatures.Enabled
+	}
+	checks = append(checks, complianceCheck{Name: "signed commits", Passed: signatures})
+
+	req, err := client.NewRequest("GET", fmt.Sprintf("repos/%s/%s/vulnerability-alerts", project.Namespace, project.Name), nil)
+	if err != nil {
+		return errorBadge("compliance", project, err)
+	}
+	req.Header.Set("Accept", "application/vnd.github.dorian-preview+json")
+	response, err = client.Do(ctx, req, nil)
+	if err != nil && !isNotFound(response) {
+		return errorBadge("compliance", project, err)
+	}
+	checks = append(checks, complianceCheck{Name: "security alerts", Passed: err == nil})
+
+	return complianceBadge(ctx, project, checks)
 }
 
-func (b *GithubProject) issues(project Project) *Badge {
+func (b *GithubProject) issues(ctx context.Context, project Project) *Badge {
 	if !isGitHub(project) {
 		return nil
 	}
 
-	githubProject, errBadge := b.getProject(project)
+	githubProject, errBadge := b.getProject(ctx, project)
 	if errBadge != nil {
 		return errBadge
 	}
@@ -196,7 +634,7 @@ func (b *GithubProject) issues(project Project) *Badge {
 		return svgBadge(project.Hoster, project.Name, "issues", "issues", "disabled", badge.ColorLightgray, project.URL, nil)
 	}
 
-	pullRequestCount, err := b.pullRequestCount(project)
+	pullRequestCount, err := b.pullRequestCount(ctx, project)
 	if err != nil {
 		return svgBadge(project.Hoster, project.Name, "issues", "issues", "Error", badge.ColorRed, project.URL+"/pulls", err)
 	}
@@ -208,15 +646,17 @@ func (b *GithubProject) issues(project Project) *Badge {
 		color = badge.ColorYellow
 	}
 
-	return svgBadge(project.Hoster, project.Name, "issues", "issues", fmt.Sprintf("%d", issueCount), color, project.URL+"/issues", nil)
+	o := svgBadge(project.Hoster, project.Name, "issues", "issues", fmt.Sprintf("%d", issueCount), color, project.URL+"/issues", nil)
+	o.Value = issueCount
+	return o
 }
 
-func (b *GithubProject) lastcommit(project Project) *Badge {
+func (b *GithubProject) lastcommit(ctx context.Context, project Project) *Badge {
 	if !isGitHub(project) {
 		return nil
 	}
 
-	githubProject, errBadge := b.getProject(project)
+	githubProject, errBadge := b.getProject(ctx, project)
 	if errBadge != nil {
 		return errBadge
 	}
@@ -234,27 +674,31 @@ func (b *GithubProject) lastcommit(project Project) *Badge {
 	case time.Now().Add(-time.Hour * 24 * 730).Before(githubProject.UpdatedAt.Time):
 		color = badge.ColorOrange
 	}
-	return svgBadge(project.Hoster, project.Name, "lastcommit", "last commit", humanize.Time(githubProject.UpdatedAt.Time), color, project.URL, nil)
+	o := svgBadge(project.Hoster, project.Name, "lastcommit", "last commit", humanize.Time(githubProject.UpdatedAt.Time), color, project.URL, nil)
+	o.Value = int(time.Since(githubProject.UpdatedAt.Time).Hours() / 24)
+	return o
 }
 
-func (b *GithubProject) stars(project Project) *Badge {
+func (b *GithubProject) stars(ctx context.Context, project Project) *Badge {
 	if !isGitHub(project) {
 		return nil
 	}
 
-	githubProject, errBadge := b.getProject(project)
+	githubProject, errBadge := b.getProject(ctx, project)
 	if errBadge != nil {
 		return errBadge
 	}
-	return svgBadge(project.Hoster, project.Name, "stars", "stars", fmt.Sprint(*githubProject.StargazersCount), badge.ColorBlue, project.URL+"/stargazers", nil)
+	o := svgBadge(project.Hoster, project.Name, "stars", "stars", fmt.Sprint(*githubProject.StargazersCount), badge.ColorBlue, project.URL+"/stargazers", nil)
+	o.Value = *githubProject.StargazersCount
+	return o
 }
 
-func (b *GithubProject) visibility(project Project) *Badge {
+func (b *GithubProject) visibility(ctx context.Context, project Project) *Badge {
 	if !isGitHub(project) {
 		return nil
 	}
 
-	githubProject, errBadge := b.getProject(project)
+	githubProject, errBadge := b.getProject(ctx, project)
 	if errBadge != nil {
 		return errBadge
 	}
@@ -275,24 +719,26 @@ func (b *GithubProject) visibility(project Project) *Badge {
 	return svgBadge(project.Hoster, project.Name, "visibility", "visibility", text+archived, color, project.URL, nil)
 }
 
-func (b *GithubProject) forks(project Project) *Badge {
+func (b *GithubProject) forks(ctx context.Context, project Project) *Badge {
 	if !isGitHub(project) {
 		return nil
 	}
 
-	githubProject, errBadge := b.getProject(project)
+	githubProject, errBadge := b.getProject(ctx, project)
 	if errBadge != nil {
 		return errBadge
 	}
-	return svgBadge(project.Hoster, project.Name, "fork", "Fork", fmt.Sprint(*githubProject.ForksCount), badge.ColorBlue, project.URL+"/network/members", nil)
+	o := svgBadge(project.Hoster, project.Name, "fork", "Fork", fmt.Sprint(*githubProject.ForksCount), badge.ColorBlue, project.URL+"/network/members", nil)
+	o.Value = *githubProject.ForksCount
+	return o
 }
 
-func (b *GithubProject) size(project Project) *Badge {
+func (b *GithubProject) size(ctx context.Context, project Project) *Badge {
 	if !isGitHub(project) {
 		return nil
 	}
 
-	githubProject, errBadge := b.getProject(project)
+	githubProject, errBadge := b.getProject(ctx, project)
 	if errBadge != nil {
 		return errBadge
 	}
@@ -310,27 +756,31 @@ func (b *GithubProject) size(project Project) *Badge {
 	case uint64(*githubProject.Size) > 1024:
 		color = badge.ColorGreen
 	}
-	return svgBadge(project.Hoster, project.Name, "reposize", "repo size", humanize.Bytes(uint64(*githubProject.Size)*1024), color, project.URL, nil)
+	o := svgBadge(project.Hoster, project.Name, "reposize", "repo size", humanize.Bytes(uint64(*githubProject.Size)*1024), color, project.URL, nil)
+	o.Value = *githubProject.Size * 1024
+	return o
 }
 
-func (b *GithubProject) watchers(project Project) *Badge {
+func (b *GithubProject) watchers(ctx context.Context, project Project) *Badge {
 	if !isGitHub(project) {
 		return nil
 	}
 
-	githubProject, errBadge := b.getProject(project)
+	githubProject, errBadge := b.getProject(ctx, project)
 	if errBadge != nil {
 		return errBadge
 	}
-	return svgBadge(project.Hoster, project.Name, "watchers", "watchers", fmt.Sprint(*githubProject.SubscribersCount), badge.ColorBlue, project.URL, nil)
+	o := svgBadge(project.Hoster, project.Name, "watchers", "watchers", fmt.Sprint(*githubProject.SubscribersCount), badge.ColorBlue, project.URL, nil)
+	o.Value = *githubProject.SubscribersCount
+	return o
 }
 
-func (b *GithubProject) license(project Project) *Badge {
+func (b *GithubProject) license(ctx context.Context, project Project) *Badge {
 	if !isGitHub(project) {
 		return nil
 	}
 
-	githubProject, errBadge := b.getProject(project)
+	githubProject, errBadge := b.getProject(ctx, project)
 
 	switch {
 	case errBadge != nil:
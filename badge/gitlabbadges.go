@@ -1,10 +1,13 @@
 package badge
 
 import (
-	"crypto/tls"
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -15,71 +18,90 @@ import (
 	"github.com/xanzy/go-gitlab"
 )
 
-var isGitLab = func(p Project) bool { return p.Hoster == "gitlab.com" || p.IsGitlab }
+var isGitLab = func(p Project) bool { return Forge(p) == "gitlab" }
 
-func InitGitLabBadges(gitlabAccessToken string) {
-	gitlabProject := NewGitLabProject(gitlabAccessToken)
+func InitGitLabBadges() {
+	gitlabProject := NewGitLabProject()
+	branchResolvers = append(branchResolvers, gitlabProject.defaultBranch)
+	coverageResolvers = append(coverageResolvers, gitlabProject.pipelineCoverage)
+	releaseResolvers = append(releaseResolvers, gitlabProject.releases)
+	topicResolvers = append(topicResolvers, gitlabProject.topics)
+	updatedResolvers = append(updatedResolvers, gitlabProject.updated)
+	fileResolvers = append(fileResolvers, gitlabProject.file)
 	badges["gitlab-branches"] = gitlabProject.branches
-	badges["gitlab-coverage"] = markdownBadge("{{.URL}}/badges/master/coverage.svg", "{{.URL}}/-/jobs/artifacts/master/file/coverage.html?job=unittests", isGitLab)
+	badges["gitlab-coverage"] = gitlabProject.coverage
 	badges["gitlab-forks"] = gitlabProject.forks
 	badges["gitlab-issues"] = gitlabProject.issues
 	badges["gitlab-lastcommit"] = gitlabProject.lastcommit
 	badges["gitlab-mergerequests"] = gitlabProject.mergerequests
-	badges["gitlab-pipeline"] = markdownBadge("{{.URL}}/badges/master/pipeline.svg", "{{.URL}}/pipelines", isGitLab)
+	badges["gitlab-compliance"] = gitlabProject.compliance
+	badges["gitlab-oldest-issue"] = gitlabProject.oldestIssue
+	badges["gitlab-mr-age"] = gitlabProject.mergeRequestAge
+	badges["gitlab-mr-waiting"] = gitlabProject.reviewWait
+	badges["gitlab-untriaged"] = gitlabProject.untriaged
+	badges["gitlab-pipeline"] = gitlabProject.pipeline
 	badges["gitlab-size"] = gitlabProject.size
 	badges["gitlab-stars"] = gitlabProject.stars
 	badges["gitlab-version"] = gitlabProject.tag
 	badges["gitlab-visibility"] = gitlabProject.visibility
+	setCost(CostClone, "gitlab-coverage")
 }
 
 type GitLabProject struct {
-	Clients           map[string]*gitlab.Client
-	locker            *locker.Locker
-	repositoryCache   sync.Map
-	gitlabAccessToken string
+	Clients         map[string]*gitlab.Client
+	locker          *locker.Locker
+	repositoryCache sync.Map
+	pipelineCache   sync.Map
+	ticketCache     sync.Map
 }
 
 var clientLock sync.Mutex
 var Insecure = false
 
-func (b *GitLabProject) GetClient(name string) (*gitlab.Client, error) {
+// GetClient returns a client for the host of a project with the token of the
+// project. Clients are shared by all projects using the same token.
+func (b *GitLabProject) GetClient(project Project) (*gitlab.Client, error) {
+	token, err := Token(project)
+	if err != nil {
+		return nil, err
+	}
+
 	clientLock.Lock()
 	defer clientLock.Unlock()
 
-	if client, ok := b.Clients[name]; ok {
+	key := project.Hoster + "\n" + token
+	if client, ok := b.Clients[key]; ok {
 		return client, nil
 	}
 
-	transCfg := &http.Transport{
-		TLSClientConfig:     &tls.Config{InsecureSkipVerify: Insecure},
-		TLSHandshakeTimeout: 10 * time.Second,
+	c := gitlab.NewClient(newHTTPClient("gitlab"), token)
+	baseURL := Credentials[project.Hoster].BaseURL
+	if baseURL == "" {
+		baseURL = "https://" + project.Hoster
 	}
-	httpClient := &http.Client{Transport: transCfg, Timeout: 10 * time.Second}
-	c := gitlab.NewClient(httpClient, b.gitlabAccessToken)
-	err := c.SetBaseURL("https://" + name)
+	err = c.SetBaseURL(baseURL)
 	if err != nil {
 		return nil, err
 	}
 
-	b.Clients[name] = c
+	b.Clients[key] = c
 
 	return c, nil
 }
 
-func NewGitLabProject(gitlabAccessToken string) *GitLabProject {
+func NewGitLabProject() *GitLabProject {
 	return &GitLabProject{
-		gitlabAccessToken: gitlabAccessToken,
-		Clients:           map[string]*gitlab.Client{},
-		locker:            locker.Initialize(),
+		Clients: map[string]*gitlab.Client{},
+		locker:  locker.Initialize(),
 	}
 }
 
-func (b *GitLabProject) GetProject(project Project) (*gitlab.Project, error) {
+func (b *GitLabProject) GetProject(ctx context.Context, project Project) (*gitlab.Project, error) {
 	if !isGitLab(project) {
 		return nil, errors.New("not a GitLab project")
 	}
 
-	client, err := b.GetClient(project.Hoster)
+	client, err := b.GetClient(project)
 	if err != nil {
 		return nil, err
 	}
@@ -92,7 +114,7 @@ func (b *GitLabProject) GetProject(project Project) (*gitlab.Project, error) {
 		t := true
 		options := &gitlab.GetProjectOptions{Statistics: &t}
 		id := strings.Trim(project.Namespace+"/"+project.Name, "/")
-		gitlabProject, _, err := client.Projects.GetProject(id, options)
+		gitlabProject, _, err := client.Projects.GetProject(id, options, gitlab.WithContext(ctx))
 		if err != nil {
 			return nil, err
 		}
@@ -102,7 +124,159 @@ func (b *GitLabProject) GetProject(project Project) (*gitlab.Project, error) {
 	return loadedProject.(*gitlab.Project), nil
 }
 
-func (b *GitLabProject) mergerequests(project Project) *Badge {
+func (b *GitLabProject) defaultBranch(ctx context.Context, project Project) (string, error) {
+	if !isGitLab(project) {
+		return "", nil
+	}
+
+	gitlabProject, err := b.GetProject(ctx, project)
+	if err != nil {
+		return "", err
+	}
+	return gitlabProject.DefaultBranch, nil
+}
+
+func (b *GitLabProject) updated(ctx context.Context, project Project) (time.Time, error) {
+	if !isGitLab(project) {
+		return time.Time{}, nil
+	}
+
+	gitlabProject, err := b.GetProject(ctx, project)
+	if err != nil || gitlabProject.LastActivityAt == nil {
+		return time.Time{}, err
+	}
+	return *gitlabProject.LastActivityAt, nil
+}
+
+func (b *GitLabProject) file(ctx context.Context, project Project, name string) ([]byte, bool, error) {
+	if !isGitLab(project) {
+		return nil, false, nil
+	}
+
+	client, err := b.GetClient(project)
+	if err != nil {
+		return nil, true, err
+	}
+	id := strings.Trim(project.Namespace+"/"+project.Name, "/")
+	options := &gitlab.GetRawFileOptions{}
+	if project.DefaultBranch != "" {
+		options.Ref = gitlab.String(project.DefaultBranch)
+	}
+	content, response, err := client.RepositoryFiles.GetRawFile(id, name, options, gitlab.WithContext(ctx))
+	if isGitLabNotFound(response) {
+		return nil, true, nil
+	}
+	if err != nil {
+		return nil, true, err
+	}
+	return content, true, nil
+}
+
+func (b *GitLabProject) topics(ctx context.Context, project Project) ([]string, error) {
+	if !isGitLab(project) {
+		return nil, nil
+	}
+
+	gitlabProject, err := b.GetProject(ctx, project)
+	if err != nil {
+		return nil, err
+	}
+	return gitlabProject.TagList, nil
+}
+
+// latestPipeline returns the latest pipeline on the default branch or nil if
+// there is none.
+func (b *GitLabProject) latestPipeline(ctx context.Context, project Project) (*gitlab.Pipeline, error) {
+	client, err := b.GetClient(project)
+	if err != nil {
+		return nil, err
+	}
+
+	b.locker.Lock("pipeline" + project.URL)
+	defer b.locker.Unlock("pipeline" + project.URL)
+
+	loadedPipeline, ok := b.pipelineCache.Load(project.URL)
+	if ok {
+		return loadedPipeline.(*gitlab.Pipeline), nil
+	}
+
+	id := strings.Trim(project.Namespace+"/"+project.Name, "/")
+	options := &gitlab.ListProjectPipelinesOptions{ListOptions: gitlab.ListOptions{PerPage: 1}}
+	if project.DefaultBranch != "" {
+		options.Ref = &project.DefaultBranch
+	}
+	pipelines, _, err := client.Pipelines.ListProjectPipelines(id, options, gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	var pipeline *gitlab.Pipeline
+	if len(pipelines) > 0 {
+		pipeline, _, err = client.Pipelines.GetPipeline(id, pipelines[0].ID, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, err
+		}
+	}
+	b.pipelineCache.Store(project.URL, pipeline)
+	return pipeline, nil
+}
+
+func (b *GitLabProject) pipeline(ctx context.Context, project Project) *Badge {
+	if !isGitLab(project) {
+		return nil
+	}
+
+	gitlabPipeline, err := b.latestPipeline(ctx, project)
+	if err != nil {
+		return errorBadge("pipeline", project, err)
+	}
+	if gitlabPipeline == nil {
+		return nil
+	}
+
+	pipeline := &Pipeline{State: PipelineUnknown, Duration: time.Duration(gitlabPipeline.Duration) * time.Second, URL: gitlabPipeline.WebURL}
+	switch gitlabPipeline.Status {
+	case "success":
+		pipeline.State = PipelinePassed
+	case "failed":
+		pipeline.State = PipelineFailed
+	case "running":
+		pipeline.State = PipelineRunning
+		if gitlabPipeline.StartedAt != nil {
+			pipeline.Duration = time.Since(*gitlabPipeline.StartedAt)
+		}
+	case "created", "waiting_for_resource", "preparing", "pending", "scheduled", "manual":
+		pipeline.State = PipelinePending
+	case "canceled", "skipped":
+		pipeline.State = PipelineCanceled
+	}
+	return pipelineBadge("pipeline", project, pipeline)
+}
+
+func (b *GitLabProject) pipelineCoverage(ctx context.Context, project Project) (*coverageResult, error) {
+	if !isGitLab(project) {
+		return nil, nil
+	}
+
+	gitlabPipeline, err := b.latestPipeline(ctx, project)
+	if err != nil || gitlabPipeline == nil || gitlabPipeline.Coverage == "" {
+		return nil, err
+	}
+	percent, err := strconv.ParseFloat(gitlabPipeline.Coverage, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &coverageResult{Percent: percent, Link: gitlabPipeline.WebURL}, nil
+}
+
+func (b *GitLabProject) coverage(ctx context.Context, project Project) *Badge {
+	if !isGitLab(project) {
+		return nil
+	}
+	return coverage(ctx, project)
+}
+
+func (b *GitLabProject) mergerequests(ctx context.Context, project Project) *Badge {
 	if !isGitLab(project) {
 		return nil
 	}
@@ -112,11 +286,14 @@ func (b *GitLabProject) mergerequests(project Project) *Badge {
 		State: &state,
 	}
 
-	client, _ := b.GetClient(project.Hoster)
+	client, err := b.GetClient(project)
+	if err != nil {
+		return svgBadge(project.Hoster, project.Name, "mergerequests", "merge requests", "Error", badge.ColorLightgrey, project.URL, err)
+	}
 
 	id := project.Namespace + "/" + project.Name
 	id = strings.Trim(id, "/")
-	_, response, err := client.MergeRequests.ListProjectMergeRequests(id, options)
+	_, response, err := client.MergeRequests.ListProjectMergeRequests(id, options, gitlab.WithContext(ctx))
 	if err != nil {
 		return svgBadge(project.Hoster, project.Name, "mergerequests", "merge requests", "Error", badge.ColorLightgrey, project.URL, err)
 	}
@@ -125,20 +302,25 @@ func (b *GitLabProject) mergerequests(project Project) *Badge {
 	if response.TotalItems > 0 {
 		color = badge.ColorYellow
 	}
-	return svgBadge(project.Hoster, project.Name, "mergerequests", "merge requests", fmt.Sprintf("%d", response.TotalItems), color, project.URL+"/-/merge_requests", nil)
+	o := svgBadge(project.Hoster, project.Name, "mergerequests", "merge requests", fmt.Sprintf("%d", response.TotalItems), color, project.URL+"/-/merge_requests", nil)
+	o.Value = response.TotalItems
+	return o
 }
 
-func (b *GitLabProject) branches(project Project) *Badge {
+func (b *GitLabProject) branches(ctx context.Context, project Project) *Badge {
 	if !isGitLab(project) {
 		return nil
 	}
 
 	options := &gitlab.ListBranchesOptions{}
-	client, _ := b.GetClient(project.Hoster)
+	client, err := b.GetClient(project)
+	if err != nil {
+		return svgBadge(project.Hoster, project.Name, "branches", "branches", "Error", badge.ColorLightgrey, project.URL, err)
+	}
 
 	id := project.Namespace + "/" + project.Name
 	id = strings.Trim(id, "/")
-	_, response, err := client.Branches.ListBranches(id, options)
+	_, response, err := client.Branches.ListBranches(id, options, gitlab.WithContext(ctx))
 	if err != nil {
 		return svgBadge(project.Hoster, project.Name, "branches", "branches", "Error", badge.ColorLightgrey, project.URL, err)
 	}
@@ -150,36 +332,233 @@ func (b *GitLabProject) branches(project Project) *Badge {
 	if response.TotalItems > 2 {
 		color = badge.ColorYellow
 	}
-	return svgBadge(project.Hoster, project.Name, "branches", "branches", fmt.Sprintf("%d", response.TotalItems), color, project.URL+"/-/branches", nil)
+	o := svgBadge(project.Hoster, project.Name, "branches", "branches", fmt.Sprintf("%d", response.TotalItems), color, project.URL+"/-/branches", nil)
+	o.Value = response.TotalItems
+	return o
+}
+
+func (b *GitLabProject) tag(ctx context.Context, project Project) *Badge {
+	if !isGitLab(project) {
+		return nil
+	}
+	return version(ctx, project)
+}
+
+func (b *GitLabProject) releases(ctx context.Context, project Project) ([]release, error) {
+	if !isGitLab(project) {
+		return nil, nil
+	}
+
+	client, err := b.GetClient(project)
+	if err != nil {
+		return nil, err
+	}
+
+	id := strings.Trim(project.Namespace+"/"+project.Name, "/")
+	var releases []release
+	options := &gitlab.ListReleasesOptions{PerPage: 100}
+	for {
+		gitlabReleases, response, err := client.Releases.ListReleases(id, options, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range gitlabReleases {
+			date := time.Time{}
+			if r.CreatedAt != nil {
+				date = *r.CreatedAt
+			}
+			releases = append(releases, release{Tag: r.TagName, Date: date, Published: true})
+		}
+		if response.NextPage == 0 {
+			return releases, nil
+		}
+		options.Page = response.NextPage
+	}
+}
+
+// tickets lists all open issues and merge requests. Merge requests without
+// notes and upvotes count as not reviewed.
+func (b *GitLabProject) tickets(ctx context.Context, project Project) ([]ticket, error) {
+	client, err := b.GetClient(project)
+	if err != nil {
+		return nil, err
+	}
+
+	b.locker.Lock("tickets" + project.URL)
+	defer b.locker.Unlock("tickets" + project.URL)
+
+	if tickets, ok := b.ticketCache.Load(project.URL); ok {
+		return tickets.([]ticket), nil
+	}
+
+	id := strings.Trim(project.Namespace+"/"+project.Name, "/")
+	state := "opened"
+	var tickets []ticket
+
+	issueOptions := &gitlab.ListProjectIssuesOptions{State: &state, ListOptions: gitlab.ListOptions{PerPage: 100}}
+	for {
+		issues, response, err := client.Issues.ListProjectIssues(id, issueOptions, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, err
+		}
+		for _, issue := range issues {
+			t := ticket{Labeled: len(issue.Labels) > 0, Assigned: len(issue.Assignees) > 0 || issue.Assignee != nil}
+			if issue.CreatedAt != nil {
+				t.Created = *issue.CreatedAt
+			}
+			tickets = append(tickets, t)
+		}
+		if response.NextPage == 0 {
+			break
+		}
+		issueOptions.Page = response.NextPage
+	}
+
+	mergeRequestOptions := &gitlab.ListProjectMergeRequestsOptions{State: &state, ListOptions: gitlab.ListOptions{PerPage: 100}}
+	for {
+		mergeRequests, response, err := client.MergeRequests.ListProjectMergeRequests(id, mergeRequestOptions, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, err
+		}
+		for _, mergeRequest := range mergeRequests {
+			t := ticket{PullRequest: true, Labeled: len(mergeRequest.Labels) > 0, Reviewed: mergeRequest.UserNotesCount > 0 || mergeRequest.Upvotes > 0}
+			if mergeRequest.CreatedAt != nil {
+				t.Created = *mergeRequest.CreatedAt
+			}
+			tickets = append(tickets, t)
+		}
+		if response.NextPage == 0 {
+			break
+		}
+		mergeRequestOptions.Page = response.NextPage
+	}
+
+	b.ticketCache.Store(project.URL, tickets)
+	return tickets, nil
 }
 
-func (b *GitLabProject) tag(project Project) *Badge {
+func (b *GitLabProject) oldestIssue(ctx context.Context, project Project) *Badge {
 	if !isGitLab(project) {
 		return nil
 	}
 
-	options := &gitlab.ListTagsOptions{}
-	client, _ := b.GetClient(project.Hoster)
+	tickets, err := b.tickets(ctx, project)
+	if err != nil {
+		return errorBadge("oldest-issue", project, err)
+	}
+	return oldestIssueBadge(project, project.URL+"/-/issues?sort=created_asc", tickets)
+}
 
-	id := project.Namespace + "/" + project.Name
-	id = strings.Trim(id, "/")
-	tags, _, err := client.Tags.ListTags(id, options)
+func (b *GitLabProject) mergeRequestAge(ctx context.Context, project Project) *Badge {
+	if !isGitLab(project) {
+		return nil
+	}
+
+	tickets, err := b.tickets(ctx, project)
 	if err != nil {
-		return svgBadge(project.Hoster, project.Name, "tag", "tag", "Error", badge.ColorLightgrey, project.URL, err)
+		return errorBadge("pr-age", project, err)
 	}
-	if len(tags) == 0 {
+	return pullRequestAgeBadge(project, "mr age", project.URL+"/-/merge_requests", tickets)
+}
+
+func (b *GitLabProject) reviewWait(ctx context.Context, project Project) *Badge {
+	if !isGitLab(project) {
 		return nil
 	}
-	tag := tags[0]
-	return svgBadge(project.Hoster, project.Name, "tag", "tag", tag.Name, badge.ColorBlue, project.URL+"/-/tags", nil)
+
+	tickets, err := b.tickets(ctx, project)
+	if err != nil {
+		return errorBadge("review-wait", project, err)
+	}
+	return reviewWaitBadge(project, "mrs awaiting review", project.URL+"/-/merge_requests", tickets)
+}
+
+func (b *GitLabProject) untriaged(ctx context.Context, project Project) *Badge {
+	if !isGitLab(project) {
+		return nil
+	}
+
+	tickets, err := b.tickets(ctx, project)
+	if err != nil {
+		return errorBadge("untriaged", project, err)
+	}
+	return untriagedBadge(project, project.URL+"/-/issues?label_name[]=None&assignee_id=None", tickets)
+}
+
+// gitlabPushRules contains the push rules checked by the compliance badge,
+// including fields missing in the go-gitlab version in use.
+type gitlabPushRules struct {
+	MemberCheck           bool `json:"member_check"`
+	PreventSecrets        bool `json:"prevent_secrets"`
+	RejectUnsignedCommits bool `json:"reject_unsigned_commits"`
+}
+
+func isGitLabNotFound(response *gitlab.Response) bool {
+	return response != nil && response.StatusCode == http.StatusNotFound
+}
+
+// compliance audits the protected branches, merge request approvals and push
+// rules of the project.
+func (b *GitLabProject) compliance(ctx context.Context, project Project) *Badge {
+	if !isGitLab(project) {
+		return nil
+	}
+
+	client, err := b.GetClient(project)
+	if err != nil {
+		return errorBadge("compliance", project, err)
+	}
+	id := strings.Trim(project.Namespace+"/"+project.Name, "/")
+
+	protectedBranches, _, err := client.ProtectedBranches.ListProtectedBranches(id, &gitlab.ListProtectedBranchesOptions{}, gitlab.WithContext(ctx))
+	if err != nil {
+		return errorBadge("compliance", project, err)
+	}
+	protected := false
+	for _, protectedBranch := range protectedBranches {
+		if match, _ := filepath.Match(protectedBranch.Name, project.DefaultBranch); match {
+			protected = true
+		}
+	}
+	checks := []complianceCheck{{Name: "protected branch " + project.DefaultBranch, Passed: protected}}
+
+	approvals, response, err := client.Projects.GetApprovalConfiguration(id, gitlab.WithContext(ctx))
+	if err != nil && !isGitLabNotFound(response) {
+		return errorBadge("compliance", project, err)
+	}
+	approvalsBeforeMerge := 0
+	if approvals != nil {
+		approvalsBeforeMerge = approvals.ApprovalsBeforeMerge
+	}
+	checks = append(checks, complianceCheck{Name: "required approvals", Passed: approvalsBeforeMerge > 0, Detail: fmt.Sprintf("%d approvals", approvalsBeforeMerge)})
+
+	req, err := client.NewRequest("GET", fmt.Sprintf("projects/%s/push_rule", url.PathEscape(id)), nil, []gitlab.OptionFunc{gitlab.WithContext(ctx)})
+	if err != nil {
+		return errorBadge("compliance", project, err)
+	}
+	var pushRules *gitlabPushRules
+	response, err = client.Do(req, &pushRules)
+	if err != nil && !isGitLabNotFound(response) {
+		return errorBadge("compliance", project, err)
+	}
+	if pushRules == nil {
+		pushRules = &gitlabPushRules{}
+	}
+	checks = append(checks,
+		complianceCheck{Name: "push rules: committer is member", Passed: pushRules.MemberCheck},
+		complianceCheck{Name: "push rules: prevent secrets", Passed: pushRules.PreventSecrets},
+		complianceCheck{Name: "push rules: signed commits", Passed: pushRules.RejectUnsignedCommits},
+	)
+
+	return complianceBadge(ctx, project, checks)
 }
 
-func (b *GitLabProject) issues(project Project) *Badge {
+func (b *GitLabProject) issues(ctx context.Context, project Project) *Badge {
 	if !isGitLab(project) {
 		return nil
 	}
 
-	gitlabProject, err := b.GetProject(project)
+	gitlabProject, err := b.GetProject(ctx, project)
 	if err != nil {
 		return errorBadge("issues", project, err)
 	}
@@ -188,19 +567,21 @@ func (b *GitLabProject) issues(project Project) *Badge {
 	if gitlabProject.OpenIssuesCount > 0 {
 		color = badge.ColorYellow
 	}
-	return svgBadge(project.Hoster, project.Name, "issues", "issues", fmt.Sprintf("%d", gitlabProject.OpenIssuesCount), color, project.URL+"/-/issues", nil)
+	o := svgBadge(project.Hoster, project.Name, "issues", "issues", fmt.Sprintf("%d", gitlabProject.OpenIssuesCount), color, project.URL+"/-/issues", nil)
+	o.Value = gitlabProject.OpenIssuesCount
+	return o
 }
 
 func errorBadge(name string, project Project, err error) *Badge {
 	return svgBadge(project.Hoster, project.Name, name, name, "Error", badge.ColorLightgrey, project.URL, err)
 }
 
-func (b *GitLabProject) lastcommit(project Project) *Badge {
+func (b *GitLabProject) lastcommit(ctx context.Context, project Project) *Badge {
 	if !isGitLab(project) {
 		return nil
 	}
 
-	gitlabProject, err := b.GetProject(project)
+	gitlabProject, err := b.GetProject(ctx, project)
 	if err != nil {
 		return errorBadge("issues", project, err)
 	}
@@ -217,27 +598,31 @@ func (b *GitLabProject) lastcommit(project Project) *Badge {
 	case time.Now().Add(-time.Hour * 24 * 730).Before(*gitlabProject.LastActivityAt):
 		color = badge.ColorOrange
 	}
-	return svgBadge(project.Hoster, project.Name, "last", "last commit", humanize.Time(*gitlabProject.LastActivityAt), color, project.URL+"/-/commits", nil)
+	o := svgBadge(project.Hoster, project.Name, "last", "last commit", humanize.Time(*gitlabProject.LastActivityAt), color, project.URL+"/-/commits", nil)
+	o.Value = int(time.Since(*gitlabProject.LastActivityAt).Hours() / 24)
+	return o
 }
 
-func (b *GitLabProject) stars(project Project) *Badge {
+func (b *GitLabProject) stars(ctx context.Context, project Project) *Badge {
 	if !isGitLab(project) {
 		return nil
 	}
 
-	gitlabProject, err := b.GetProject(project)
+	gitlabProject, err := b.GetProject(ctx, project)
 	if err != nil {
 		return errorBadge("issues", project, err)
 	}
-	return svgBadge(project.Hoster, project.Name, "stars", "stars", fmt.Sprint(gitlabProject.StarCount), badge.ColorBlue, project.URL+"/-/starrers", nil)
+	o := svgBadge(project.Hoster, project.Name, "stars", "stars", fmt.Sprint(gitlabProject.StarCount), badge.ColorBlue, project.URL+"/-/starrers", nil)
+	o.Value = gitlabProject.StarCount
+	return o
 }
 
-func (b *GitLabProject) visibility(project Project) *Badge {
+func (b *GitLabProject) visibility(ctx context.Context, project Project) *Badge {
 	if !isGitLab(project) {
 		return nil
 	}
 
-	gitlabProject, err := b.GetProject(project)
+	gitlabProject, err := b.GetProject(ctx, project)
 	if err != nil {
 		return errorBadge("issues", project, err)
 	}
@@ -251,24 +636,26 @@ func (b *GitLabProject) visibility(project Project) *Badge {
 	return svgBadge(project.Hoster, project.Name, "visibility", "visibility", string(gitlabProject.Visibility), color, project.URL, nil)
 }
 
-func (b *GitLabProject) forks(project Project) *Badge {
+func (b *GitLabProject) forks(ctx context.Context, project Project) *Badge {
 	if !isGitLab(project) {
 		return nil
 	}
 
-	gitlabProject, err := b.GetProject(project)
+	gitlabProject, err := b.GetProject(ctx, project)
 	if err != nil {
 		return errorBadge("issues", project, err)
 	}
-	return svgBadge(project.Hoster, project.Name, "fork", "Fork", fmt.Sprint(gitlabProject.ForksCount), badge.ColorBlue, project.URL+"/-/forks", nil)
+	o := svgBadge(project.Hoster, project.Name, "fork", "Fork", fmt.Sprint(gitlabProject.ForksCount), badge.ColorBlue, project.URL+"/-/forks", nil)
+	o.Value = gitlabProject.ForksCount
+	return o
 }
 
-func (b *GitLabProject) size(project Project) *Badge {
+func (b *GitLabProject) size(ctx context.Context, project Project) *Badge {
 	if !isGitLab(project) {
 		return nil
 	}
 
-	gitlabProject, err := b.GetProject(project)
+	gitlabProject, err := b.GetProject(ctx, project)
 	if err != nil {
 		return errorBadge("issues", project, err)
 	}
@@ -286,5 +673,7 @@ func (b *GitLabProject) size(project Project) *Badge {
 	case gitlabProject.Statistics.RepositorySize > 1024*1024:
 		color = badge.ColorGreen
 	}
-	return svgBadge(project.Hoster, project.Name, "reposize", "repo size", humanize.Bytes(uint64(gitlabProject.Statistics.RepositorySize)), color, project.URL, nil)
+	o := svgBadge(project.Hoster, project.Name, "reposize", "repo size", humanize.Bytes(uint64(gitlabProject.Statistics.RepositorySize)), color, project.URL, nil)
+	o.Value = gitlabProject.Statistics.RepositorySize
+	return o
 }
@@ -0,0 +1,236 @@
+package badge
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/enfipy/locker"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/narqo/go-badge"
+)
+
+func InitHistoryBadges() {
+	badges["commits-30d"] = commits30d
+	badges["commits-90d"] = commits90d
+	badges["authors"] = authors
+	badges["busfactor"] = busfactor
+	badges["firstcommit"] = firstcommit
+	badges["activity"] = activity
+	setCost(CostClone, "commits-30d", "commits-90d", "authors", "busfactor", "firstcommit", "activity")
+	setIncremental("firstcommit")
+}
+
+// historyStats are computed from the cloned history of the checked out branch.
+type historyStats struct {
+	Commits30   int
+	Commits90   int
+	Authors     int
+	BusFactor   int
+	FirstCommit time.Time
+	Days        map[string]int
+}
+
+var historyCache sync.Map
+var historyLocker = locker.Initialize()
+
+func history(ctx context.Context, project Project) (*historyStats, error) {
+	historyLocker.Lock(project.URL)
+	defer historyLocker.Unlock(project.URL)
+
+	if stats, ok := historyCache.Load(project.URL); ok {
+		return stats.(*historyStats), nil
+	}
+
+	projectPath, err := download(ctx, project)
+	if err != nil {
+		return nil, err
+	}
+	repository, err := git.PlainOpen(projectPath)
+	if err != nil {
+		return nil, err
+	}
+	commits, err := repository.Log(&git.LogOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	stats := &historyStats{Days: map[string]int{}}
+	authorCommits := map[string]int{}
+	err = commits.ForEach(func(commit *object.Commit) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		when := commit.Author.When
+		if stats.FirstCommit.IsZero() || when.Before(stats.FirstCommit) {
+			stats.FirstCommit = when
+		}
+		if when.After(now.AddDate(0, 0, -30)) {
+			stats.Commits30++
+		}
+		if when.After(now.AddDate(0, 0, -90)) {
+			stats.Commits90++
+		}
+		if when.After(now.AddDate(-1, 0, 0)) {
+			authorCommits[strings.ToLower(commit.Author.Email)]++
+			stats.Days[when.UTC().Format("2006-01-02")]++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	stats.Authors = len(authorCommits)
+	stats.BusFactor = busFactor(authorCommits)
+
+	historyCache.Store(project.URL, stats)
+	return stats, nil
+}
+
+// busFactor returns the smallest number of authors that together wrote at
+// least half of the given commits.
+func busFactor(authorCommits map[string]int) int {
+	var counts []int
+	total := 0
+	for _, count := range authorCommits {
+		counts = append(counts, count)
+		total += count
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(counts)))
+
+	covered := 0
+	for i, count := range counts {
+		covered += count
+		if covered*2 >= total {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+func commitsBadge(name, label string, count int, project Project) *Badge {
+	color := badge.ColorRed
+	switch {
+	case count > 10:
+		color = badge.ColorBrightgreen
+	case count > 3:
+		color = badge.ColorGreen
+	case count > 0:
+		color = badge.ColorYellow
+	}
+	b := svgBadge(project.Hoster, project.Name, name, label, fmt.Sprint(count), color, project.URL, nil)
+	b.Value = count
+	return b
+}
+
+func commits30d(ctx context.Context, project Project) *Badge {
+	stats, err := history(ctx, project)
+	if err != nil {
+		return errorBadge("commits-30d", project, err)
+	}
+	return commitsBadge("commits-30d", "commits 30d", stats.Commits30, project)
+}
+
+func commits90d(ctx context.Context, project Project) *Badge {
+	stats, err := history(ctx, project)
+	if err != nil {
+		return errorBadge("commits-90d", project, err)
+	}
+	return commitsBadge("commits-90d", "commits 90d", stats.Commits90, project)
+}
+
+func authors(ctx context.Context, project Project) *Badge {
+	stats, err := history(ctx, project)
+	if err != nil {
+		return errorBadge("authors", project, err)
+	}
+	b := svgBadge(project.Hoster, project.Name, "authors", "authors 1y", fmt.Sprint(stats.Authors), badge.ColorBlue, project.URL, nil)
+	b.Value = stats.Authors
+	return b
+}
+
+func busfactor(ctx context.Context, project Project) *Badge {
+	stats, err := history(ctx, project)
+	if err != nil {
+		return errorBadge("busfactor", project, err)
+	}
+
+	color := badge.ColorBrightgreen
+	switch stats.BusFactor {
+	case 0:
+		color = badge.ColorLightgrey
+	case 1:
+		color = badge.ColorRed
+	case 2:
+		color = badge.ColorYellow
+	}
+	b := svgBadge(project.Hoster, project.Name, "busfactor", "bus factor", fmt.Sprint(stats.BusFactor), color, project.URL, nil)
+	b.Value = stats.BusFactor
+	return b
+}
+
+func firstcommit(ctx context.Context, project Project) *Badge {
+	stats, err := history(ctx, project)
+	if err != nil {
+		return errorBadge("firstcommit", project, err)
+	}
+	if stats.FirstCommit.IsZero() {
+		return nil
+	}
+	return svgBadge(project.Hoster, project.Name, "firstcommit", "first commit", stats.FirstCommit.Format("2006-01-02"), badge.ColorBlue, project.URL, nil)
+}
+
+var activityColors = []string{"#ebedf0", "#9be9a8", "#40c463", "#30a14e", "#216e39"}
+
+// activity renders a heatmap of the commits of the last year with one column
+// per week and one row per weekday.
+func activity(ctx context.Context, project Project) *Badge {
+	stats, err := history(ctx, project)
+	if err != nil {
+		return errorBadge("activity", project, err)
+	}
+
+	max := 0
+	for _, count := range stats.Days {
+		if count > max {
+			max = count
+		}
+	}
+
+	const cell, gap, weeks = 10, 2, 53
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	start := today.AddDate(0, 0, -(weeks-1)*7-int(today.Weekday()))
+
+	var svg bytes.Buffer
+	fmt.Fprintf(&svg, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d">`, weeks*(cell+gap), 7*(cell+gap))
+	for day := start; !day.After(today); day = day.AddDate(0, 0, 1) {
+		week := int(day.Sub(start).Hours()/24) / 7
+		count := stats.Days[day.Format("2006-01-02")]
+		level := 0
+		if count > 0 {
+			level = 1 + (count-1)*(len(activityColors)-1)/max
+		}
+		fmt.Fprintf(&svg, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"><title>%s: %d commits</title></rect>`,
+			week*(cell+gap), int(day.Weekday())*(cell+gap), cell, cell, activityColors[level], day.Format("2006-01-02"), count)
+	}
+	svg.WriteString(`</svg>`)
+
+	err = writeReport(ctx, filepath.Join("badges", project.Hoster, project.Name, "activity.svg"), svg.Bytes())
+	if err != nil {
+		return errorBadge("activity", project, err)
+	}
+
+	return &Badge{
+		URL:   fmt.Sprintf("badges/%s/%s/activity.svg", project.Hoster, project.Name),
+		Link:  project.URL,
+		Title: "activity",
+	}
+}
@@ -0,0 +1,229 @@
+package badge
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/narqo/go-badge"
+)
+
+// LicensePolicy configures the license badge. Matches below Confidence are
+// not recognized. If Allow is set, the licenses of vendored dependencies must
+// be in the list.
+type LicensePolicy struct {
+	Allow      []string `yaml:"allow,omitempty"`
+	Confidence float64  `yaml:"confidence,omitempty"`
+}
+
+var Licenses = LicensePolicy{Confidence: 0.9}
+
+func InitLicenseBadges() {
+	badges["license"] = localLicense
+	setCost(CostClone, "license")
+	setIncremental("license")
+	setSettings(func() interface{} { return Licenses }, "license")
+}
+
+// licenseFileRe matches license files like LICENSE, COPYING.LESSER,
+// LICENSE-MIT or UNLICENSE.txt, but not sources like license.go.
+var licenseFileRe = regexp.MustCompile(`(?i)^(?:(?:un)?licen[cs]e|copying)(?:[-_.](?:mit|apache|bsd|gpl|lgpl|agpl|mpl|isc|lesser|lib)[-\w]*(?:\.\d+)*)?(?:\.(?:md|markdown|txt|rst))?$`)
+
+var licenseWordRe = regexp.MustCompile(`[a-z0-9]+`)
+
+func bigrams(text string) map[string]bool {
+	words := licenseWordRe.FindAllString(strings.ToLower(text), -1)
+	pairs := map[string]bool{}
+	for i := 1; i < len(words); i++ {
+		pairs[words[i-1]+" "+words[i]] = true
+	}
+	return pairs
+}
+
+var corpusOnce sync.Once
+var corpusBigrams map[string]map[string]bool
+var corpusIDs []string
+
+type licenseMatch struct {
+	ID         string
+	Confidence float64
+}
+
+// detectLicense compares a license text with the corpus. The confidence is
+// the share of word pairs of a corpus license that appear in the text, so
+// copyright lines and appendices do not lower it.
+func detectLicense(content []byte) licenseMatch {
+	corpusOnce.Do(func() {
+		corpusBigrams = map[string]map[string]bool{}
+		for id, text := range licenseCorpus {
+			corpusBigrams[id] = bigrams(text)
+			corpusIDs = append(corpusIDs, id)
+		}
+		sort.Strings(corpusIDs)
+	})
+
+	text := bigrams(string(content))
+	var matches []licenseMatch
+	matched := map[string]int{}
+	for _, id := range corpusIDs {
+		for pair := range corpusBigrams[id] {
+			if text[pair] {
+				matched[id]++
+			}
+		}
+		matches = append(matches, licenseMatch{ID: id, Confidence: float64(matched[id]) / float64(len(corpusBigrams[id]))})
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Confidence > matches[j].Confidence })
+	if len(matches) == 0 {
+		return licenseMatch{}
+	}
+
+	// Licenses that extend others, like BSD-3-Clause, contain most of the
+	// shorter license. Among close matches the one explaining more of the
+	// text wins.
+	best := matches[0]
+	for _, match := range matches[1:] {
+		if match.Confidence >= matches[0].Confidence-0.1 && matched[match.ID] > matched[best.ID] {
+			best = match
+		}
+	}
+	return best
+}
+
+func (m licenseMatch) String() string {
+	return fmt.Sprintf("%s (%.0f%%)", m.ID, m.Confidence*100)
+}
+
+func (p LicensePolicy) allowed(id string) bool {
+	return containsFold(p.Allow, id)
+}
+
+// dependencyLicenses detects the licenses of the dependencies in vendor and
+// node_modules directories, keyed by the directory of the dependency.
+func dependencyLicenses(projectPath string) (map[string][]licenseMatch, error) {
+	dependencies := map[string][]licenseMatch{}
+	err := filepath.Walk(projectPath, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, _ := filepath.Rel(projectPath, p)
+		dir := filepath.ToSlash(filepath.Dir(rel))
+		vendored := strings.HasPrefix(dir, "vendor/") || strings.Contains(dir, "node_modules/")
+		if !vendored || !licenseFileRe.MatchString(info.Name()) || info.Size() > 1024*1024 {
+			return nil
+		}
+		content, err := ioutil.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		dependencies[dir] = append(dependencies[dir], detectLicense(content))
+		return nil
+	})
+	return dependencies, err
+}
+
+// localLicense detects the license of a project from the license files in the
+// root of the clone, independent of the forge.
+func localLicense(ctx context.Context, project Project) *Badge {
+	projectPath, err := download(ctx, project)
+	if err != nil {
+		return errorBadge("license", project, err)
+	}
+
+	files, err := ioutil.ReadDir(projectPath)
+	if err != nil {
+		return errorBadge("license", project, err)
+	}
+
+	var report bytes.Buffer
+	var licenseFile string
+	found := 0
+	detected := map[string]bool{}
+	for _, file := range files {
+		if file.IsDir() || !licenseFileRe.MatchString(file.Name()) {
+			continue
+		}
+		content, err := ioutil.ReadFile(filepath.Join(projectPath, file.Name()))
+		if err != nil {
+			return errorBadge("license", project, err)
+		}
+		match := detectLicense(content)
+		found++
+		fmt.Fprintf(&report, "%s: %s\n", file.Name(), match)
+		if match.Confidence >= Licenses.Confidence {
+			detected[match.ID] = true
+			if licenseFile == "" {
+				licenseFile = file.Name()
+			}
+		}
+	}
+
+	// The LGPL is distributed together with the GPL it supplements.
+	if detected["LGPL-3.0"] {
+		delete(detected, "GPL-3.0")
+	}
+	if detected["LGPL-2.1"] {
+		delete(detected, "GPL-2.0")
+	}
+	var ids []string
+	for id := range detected {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	violations := 0
+	if len(Licenses.Allow) > 0 {
+		dependencies, err := dependencyLicenses(projectPath)
+		if err != nil {
+			return errorBadge("license", project, err)
+		}
+		var dirs []string
+		for dir := range dependencies {
+			dirs = append(dirs, dir)
+		}
+		sort.Strings(dirs)
+		for _, dir := range dirs {
+			ok := false
+			var matches []string
+			for _, match := range dependencies[dir] {
+				ok = ok || (match.Confidence >= Licenses.Confidence && Licenses.allowed(match.ID))
+				matches = append(matches, match.String())
+			}
+			if !ok {
+				violations++
+				fmt.Fprintf(&report, "%s: %s not allowed\n", dir, strings.Join(matches, ", "))
+			}
+		}
+	}
+
+	licenseLog := filepath.Join("badges", project.Hoster, project.Name, "license.txt")
+	var b *Badge
+	switch {
+	case found == 0:
+		b = svgBadge(project.Hoster, project.Name, "license", "license", "no License", badge.ColorRed, project.URL, nil)
+	case len(ids) == 0:
+		b = svgBadge(project.Hoster, project.Name, "license", "license", "not recognized", badge.ColorLightgray, licenseLog, nil)
+	case violations > 0:
+		b = svgBadge(project.Hoster, project.Name, "license", "license", fmt.Sprintf("%s, %d deps", strings.Join(ids, " / "), violations), badge.ColorOrange, licenseLog, nil)
+	default:
+		b = svgBadge(project.Hoster, project.Name, "license", "license", strings.Join(ids, " / "), badge.ColorBlue, fileURL(project, licenseFile), nil)
+	}
+	if report.Len() > 0 {
+		_ = writeReport(ctx, licenseLog, report.Bytes())
+	}
+	b.Value = strings.Join(ids, " / ")
+	return b
+}
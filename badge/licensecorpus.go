@@ -0,0 +1,282 @@
+package badge
+
+// licenseCorpus holds the texts of common SPDX licenses without their
+// copyright lines. Long licenses are cut after their first paragraphs, which
+// are enough to tell them apart.
+var licenseCorpus = map[string]string{
+	"AGPL-3.0": `The GNU Affero General Public License is a free, copyleft license for
+software and other kinds of works, specifically designed to ensure
+cooperation with the community in the case of network server software. The
+licenses for most software and other practical works are designed to take
+away your freedom to share and change the works. By contrast, our General
+Public Licenses are intended to guarantee your freedom to share and change
+all versions of a program--to make sure it remains free software for all its
+users. When we speak of free software, we are referring to freedom, not
+price. Our General Public Licenses are designed to make sure that you have
+the freedom to distribute copies of free software (and charge for them if
+you wish), that you receive source code or can get it if you want it, that
+you can change the software or use pieces of it in new free programs, and
+that you know you can do these things. Developers that use our General
+Public Licenses protect your rights with two steps: (1) assert copyright on
+the software, and (2) offer you this License which gives you legal
+permission to copy, distribute and/or modify the software. A secondary
+benefit of defending all users' freedom is that improvements made in
+alternate versions of the program, if they receive widespread use, become
+available for other developers to incorporate. Many developers of free
+software are heartened and encouraged by the resulting cooperation. However,
+in the case of software used on network servers, this result may fail to
+come about. The GNU General Public License permits making a modified version
+and letting the public access it on a server without ever releasing its
+source code to the public. The GNU Affero General Public License is designed
+specifically to ensure that,`,
+	"Apache-2.0": `TERMS AND CONDITIONS FOR USE, REPRODUCTION, AND DISTRIBUTION 1. Definitions.
+"License" shall mean the terms and conditions for use, reproduction, and
+distribution as defined by Sections 1 through 9 of this document. "Licensor"
+shall mean the copyright owner or entity authorized by the copyright owner
+that is granting the License. "Legal Entity" shall mean the union of the
+acting entity and all other entities that control, are controlled by, or are
+under common control with that entity. For the purposes of this definition,
+"control" means (i) the power, direct or indirect, to cause the direction or
+management of such entity, whether by contract or otherwise, or (ii)
+ownership of fifty percent (50%) or more of the outstanding shares, or (iii)
+beneficial ownership of such entity. "You" (or "Your") shall mean an
+individual or Legal Entity exercising permissions granted by this License.
+"Source" form shall mean the preferred form for making modifications,
+including but not limited to software source code, documentation source, and
+configuration files. "Object" form shall mean any form resulting from
+mechanical transformation or translation of a Source form, including but not
+limited to compiled object code, generated documentation, and conversions to
+other media types. "Work" shall mean the work of authorship, whether in
+Source or Object form, made available under the License, as indicated by a
+copyright notice that is included in or attached to the work (an example is
+provided in the Appendix below). "Derivative Works" shall mean any work,
+whether in Source or Object form, that is based on (or derived from) the
+Work and for which the editorial revisions, annotations, elaborations, or
+other modifications represent, as a whole, an original work of authorship.
+For the purposes of this License, Derivative Works shall not include works
+that remain separable from, or merely link (or bind by`,
+	"BSD-2-Clause": `Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+1. Redistributions of source code must retain the above copyright notice,
+this list of conditions and the following disclaimer. 2. Redistributions in
+binary form must reproduce the above copyright notice, this list of
+conditions and the following disclaimer in the documentation and/or other
+materials provided with the distribution. THIS SOFTWARE IS PROVIDED BY THE
+COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED
+WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF
+MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO
+EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF
+THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.`,
+	"BSD-3-Clause": `Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+1. Redistributions of source code must retain the above copyright notice,
+this list of conditions and the following disclaimer. 2. Redistributions in
+binary form must reproduce the above copyright notice, this list of
+conditions and the following disclaimer in the documentation and/or other
+materials provided with the distribution. 3. Neither the name of the
+copyright holder nor the names of its contributors may be used to endorse or
+promote products derived from this software without specific prior written
+permission. THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND
+CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT
+NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A
+PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL,
+EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS;
+OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR
+OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF
+ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.`,
+	"CC0-1.0": `The laws of most jurisdictions throughout the world automatically confer
+exclusive Copyright and Related Rights (defined below) upon the creator and
+subsequent owner(s) (each and all, an "owner") of an original work of
+authorship and/or a database (each, a "Work"). Certain owners wish to
+permanently relinquish those rights to a Work for the purpose of
+contributing to a commons of creative, cultural and scientific works
+("Commons") that the public can reliably and without fear of later claims of
+infringement build upon, modify, incorporate in other works, reuse and
+redistribute as freely as possible in any form whatsoever and for any
+purposes, including without limitation commercial purposes. These owners may
+contribute to the Commons to promote the ideal of a free culture and the
+further production of creative, cultural and scientific works, or to gain
+reputation or greater distribution for their Work in part through the use
+and efforts of others. For these and/or other purposes and motivations, and
+without any expectation of additional consideration or compensation, the
+person associating CC0 with a Work (the "Affirmer"), to the extent that he
+or she is an owner of Copyright and Related Rights in the Work, voluntarily
+elects to apply CC0 to the Work and publicly distribute the Work under its
+terms, with knowledge of his or her Copyright and Related Rights in the Work
+and the meaning and intended legal effect of CC0 on those rights. 1.
+Copyright and Related Rights. A Work made available under CC0 may be
+protected by copyright and related or neighboring rights ("Copyright and
+Related Rights"). Copyright and Related Rights include, but are not limited
+to, the following: i. the right to reproduce, adapt, distribute, perform,
+display, communicate, and translate a Work; ii. moral rights retained by the
+original author(s) and/or performer(s); iii. publicity and privacy rights`,
+	"GPL-2.0": `Preamble The licenses for most software are designed to take away your
+freedom to share and change it. By contrast, the GNU General Public License
+is intended to guarantee your freedom to share and change free software--to
+make sure the software is free for all its users. This General Public
+License applies to most of the Free Software Foundation's software and to
+any other program whose authors commit to using it. (Some other Free
+Software Foundation software is covered by the GNU Lesser General Public
+License instead.) You can apply it to your programs, too. When we speak of
+free software, we are referring to freedom, not price. Our General Public
+Licenses are designed to make sure that you have the freedom to distribute
+copies of free software (and charge for this service if you wish), that you
+receive source code or can get it if you want it, that you can change the
+software or use pieces of it in new free programs; and that you know you can
+do these things. To protect your rights, we need to make restrictions that
+forbid anyone to deny you these rights or to ask you to surrender the
+rights. These restrictions translate to certain responsibilities for you if
+you distribute copies of the software, or if you modify it. For example, if
+you distribute copies of such a program, whether gratis or for a fee, you
+must give the recipients all the rights that you have. You must make sure
+that they, too, receive or can get the source code. And you must show them
+these terms so they know their rights. We protect your rights with two
+steps: (1) copyright the software, and (2) offer you this license which
+gives you legal permission to copy, distribute and/or modify the software.`,
+	"GPL-3.0": `Preamble The GNU General Public License is a free, copyleft license for
+software and other kinds of works. The licenses for most software and other
+practical works are designed to take away your freedom to share and change
+the works. By contrast, the GNU General Public License is intended to
+guarantee your freedom to share and change all versions of a program--to
+make sure it remains free software for all its users. We, the Free Software
+Foundation, use the GNU General Public License for most of our software; it
+applies also to any other work released this way by its authors. You can
+apply it to your programs, too. When we speak of free software, we are
+referring to freedom, not price. Our General Public Licenses are designed to
+make sure that you have the freedom to distribute copies of free software
+(and charge for them if you wish), that you receive source code or can get
+it if you want it, that you can change the software or use pieces of it in
+new free programs, and that you know you can do these things. To protect
+your rights, we need to prevent others from denying you these rights or
+asking you to surrender the rights. Therefore, you have certain
+responsibilities if you distribute copies of the software, or if you modify
+it: responsibilities to respect the freedom of others. For example, if you
+distribute copies of such a program, whether gratis or for a fee, you must
+pass on to the recipients the same freedoms that you received. You must make
+sure that they, too, receive or can get the source code. And you must show
+them these terms so they know their rights. Developers that use the GNU GPL
+protect your rights with two steps: (1) assert`,
+	"ISC": `Permission to use, copy, modify, and/or distribute this software for any
+purpose with or without fee is hereby granted, provided that the above
+copyright notice and this permission notice appear in all copies. THE
+SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY
+AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM
+LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE
+OR OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR
+PERFORMANCE OF THIS SOFTWARE.`,
+	"LGPL-2.1": `[This is the first released version of the Lesser GPL. It also counts as the
+successor of the GNU Library Public License, version 2, hence the version
+number 2.1.] Preamble The licenses for most software are designed to take
+away your freedom to share and change it. By contrast, the GNU General
+Public Licenses are intended to guarantee your freedom to share and change
+free software--to make sure the software is free for all its users. This
+license, the Lesser General Public License, applies to some specially
+designated software packages--typically libraries--of the Free Software
+Foundation and other authors who decide to use it. You can use it too, but
+we suggest you first think carefully about whether this license or the
+ordinary General Public License is the better strategy to use in any
+particular case, based on the explanations below. When we speak of free
+software, we are referring to freedom of use, not price. Our General Public
+Licenses are designed to make sure that you have the freedom to distribute
+copies of free software (and charge for this service if you wish); that you
+receive source code or can get it if you want it; that you can change the
+software and use pieces of it in new free programs; and that you are
+informed that you can do these things. To protect your rights, we need to
+make restrictions that forbid distributors to deny you these rights or to
+ask you to surrender these rights. These restrictions translate to certain
+responsibilities for you if you distribute copies of the library or if you
+modify it. For example, if you distribute copies of the library, whether
+gratis or for a fee, you must give the recipients all the rights that we
+gave you. You must make sure that they,`,
+	"LGPL-3.0": `This version of the GNU Lesser General Public License incorporates the terms
+and conditions of version 3 of the GNU General Public License, supplemented
+by the additional permissions listed below. 0. Additional Definitions. As
+used herein, "this License" refers to version 3 of the GNU Lesser General
+Public License, and the "GNU GPL" refers to version 3 of the GNU General
+Public License. "The Library" refers to a covered work governed by this
+License, other than an Application or a Combined Work as defined below. An
+"Application" is any work that makes use of an interface provided by the
+Library, but which is not otherwise based on the Library. Defining a
+subclass of a class defined by the Library is deemed a mode of using an
+interface provided by the Library. A "Combined Work" is a work produced by
+combining or linking an Application with the Library. The particular version
+of the Library with which the Combined Work was made is also called the
+"Linked Version". The "Minimal Corresponding Source" for a Combined Work
+means the Corresponding Source for the Combined Work, excluding any source
+code for portions of the Combined Work that, considered in isolation, are
+based on the Application, and not on the Linked Version. The "Corresponding
+Application Code" for a Combined Work means the object code and/or source
+code for the Application, including any data and utility programs needed for
+reproducing the Combined Work from the Application, but excluding the System
+Libraries of the Combined Work. 1. Exception to Section 3 of the GNU GPL.
+You may convey a covered work under sections 3 and 4 of this License without
+being bound by section 3 of the GNU GPL. 2. Conveying Modified Versions. If
+you modify a copy of the Library, and, in your modifications, a facility`,
+	"MIT": `Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to
+deal in the Software without restriction, including without limitation the
+rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+sell copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions: The above copyright
+notice and this permission notice shall be included in all copies or
+substantial portions of the Software. THE SOFTWARE IS PROVIDED "AS IS",
+WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED
+TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE
+LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF
+CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.`,
+	"MPL-2.0": `1. Definitions -------------- 1.1. "Contributor" means each individual or
+legal entity that creates, contributes to the creation of, or owns Covered
+Software. 1.2. "Contributor Version" means the combination of the
+Contributions of others (if any) used by a Contributor and that particular
+Contributor's Contribution. 1.3. "Contribution" means Covered Software of a
+particular Contributor. 1.4. "Covered Software" means Source Code Form to
+which the initial Contributor has attached the notice in Exhibit A, the
+Executable Form of such Source Code Form, and Modifications of such Source
+Code Form, in each case including portions thereof. 1.5. "Incompatible With
+Secondary Licenses" means (a) that the initial Contributor has attached the
+notice described in Exhibit B to the Covered Software; or (b) that the
+Covered Software was made available under the terms of version 1.1 or
+earlier of the License, but not also under the terms of a Secondary License.
+1.6. "Executable Form" means any form of the work other than Source Code
+Form. 1.7. "Larger Work" means a work that combines Covered Software with
+other material, in a separate file or files, that is not Covered Software.
+1.8. "License" means this document. 1.9. "Licensable" means having the right
+to grant, to the maximum extent possible, whether at the time of the initial
+grant or subsequently, any and all of the rights conveyed by this License.
+1.10. "Modifications" means any of the following: (a) any file in Source
+Code Form that results from an addition to, deletion from, or modification
+of the contents of Covered Software; or (b) any new file in Source Code Form
+that contains any Covered Software. 1.11. "Patent Claims" of a Contributor
+means any patent claim(s), including without limitation, method, process,
+and apparatus claims, in any patent Licensable by such Contributor that
+would be infringed, but for the grant of`,
+	"Unlicense": `This is free and unencumbered software released into the public domain.
+Anyone is free to copy, modify, publish, use, compile, sell, or distribute
+this software, either in source code form or as a compiled binary, for any
+purpose, commercial or non-commercial, and by any means. In jurisdictions
+that recognize copyright laws, the author or authors of this software
+dedicate any and all copyright interest in the software to the public
+domain. We make this dedication for the benefit of the public at large and
+to the detriment of our heirs and successors. We intend this dedication to
+be an overt act of relinquishment in perpetuity of all present and future
+rights to this software under copyright law. THE SOFTWARE IS PROVIDED "AS
+IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT
+LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR
+PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS BE LIABLE FOR ANY
+CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE
+USE OR OTHER DEALINGS IN THE SOFTWARE. For more information, please refer to
+<https://unlicense.org>`,
+}
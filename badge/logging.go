@@ -0,0 +1,213 @@
+package badge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Level is the severity of a log entry.
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = []string{"debug", "info", "warn", "error"}
+
+func (l Level) String() string {
+	if l < LevelDebug || l > LevelError {
+		return strconv.Itoa(int(l))
+	}
+	return levelNames[l]
+}
+
+// ParseLevel parses debug, info, warn or error.
+func ParseLevel(s string) (Level, error) {
+	for i, name := range levelNames {
+		if strings.EqualFold(s, name) {
+			return Level(i), nil
+		}
+	}
+	return LevelInfo, fmt.Errorf("unknown log level %q", s)
+}
+
+type logOutput struct {
+	lock  sync.Mutex
+	w     io.Writer
+	level Level
+	json  bool
+}
+
+// Logger writes leveled entries with key value fields as text or as JSON
+// lines. Known tokens are removed from all messages and values.
+type Logger struct {
+	out    *logOutput
+	fields []interface{}
+}
+
+// Log is the logger of the dashboard.
+var Log = NewLogger(os.Stderr, LevelInfo, false)
+
+// NewLogger returns a logger writing entries of at least level to w.
+func NewLogger(w io.Writer, level Level, json bool) *Logger {
+	return &Logger{out: &logOutput{w: w, level: level, json: json}}
+}
+
+// With returns a logger adding the key value pairs to all entries.
+func (l *Logger) With(kv ...interface{}) *Logger {
+	fields := append(append([]interface{}{}, l.fields...), kv...)
+	return &Logger{out: l.out, fields: fields}
+}
+
+// Enabled reports whether entries of the level are written.
+func (l *Logger) Enabled(level Level) bool {
+	return level >= l.out.level
+}
+
+func (l *Logger) Debug(msg string, kv ...interface{}) { l.log(LevelDebug, msg, kv) }
+func (l *Logger) Info(msg string, kv ...interface{})  { l.log(LevelInfo, msg, kv) }
+func (l *Logger) Warn(msg string, kv ...interface{})  { l.log(LevelWarn, msg, kv) }
+func (l *Logger) Error(msg string, kv ...interface{}) { l.log(LevelError, msg, kv) }
+
+// Fatal logs an error and exits.
+func (l *Logger) Fatal(msg string, kv ...interface{}) {
+	l.log(LevelError, msg, kv)
+	os.Exit(1)
+}
+
+func (l *Logger) log(level Level, msg string, kv []interface{}) {
+	if !l.Enabled(level) {
+		return
+	}
+	fields := append(append([]interface{}{}, l.fields...), kv...)
+	if len(fields)%2 != 0 {
+		fields = append(fields, "")
+	}
+
+	var line []byte
+	now := time.Now().UTC()
+	if l.out.json {
+		entry := map[string]interface{}{"time": now.Format(time.RFC3339Nano), "level": level.String(), "msg": RedactString(msg)}
+		for i := 0; i < len(fields); i += 2 {
+			entry[fmt.Sprint(fields[i])] = jsonValue(fields[i+1])
+		}
+		line, _ = json.Marshal(entry)
+	} else {
+		var b strings.Builder
+		fmt.Fprintf(&b, "%s %-5s %s", now.Format(time.RFC3339), strings.ToUpper(level.String()), RedactString(msg))
+		for i := 0; i < len(fields); i += 2 {
+			fmt.Fprintf(&b, " %v=%s", fields[i], textValue(fields[i+1]))
+		}
+		line = []byte(b.String())
+	}
+
+	l.out.lock.Lock()
+	defer l.out.lock.Unlock()
+	_, _ = l.out.w.Write(append(line, '\n'))
+}
+
+func textValue(v interface{}) string {
+	var s string
+	switch v := v.(type) {
+	case error:
+		s = Redact(v).Error()
+	case fmt.Stringer:
+		s = v.String()
+	default:
+		s = fmt.Sprint(v)
+	}
+	s = RedactString(s)
+	if s == "" || strings.ContainsAny(s, " \t\n\"=") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// jsonValue keeps numbers and booleans, durations are written in seconds.
+func jsonValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case time.Duration:
+		return v.Seconds()
+	case error:
+		return Redact(v).Error()
+	case string:
+		return RedactString(v)
+	case int, int32, int64, uint, uint64, float64, bool:
+		return v
+	case fmt.Stringer:
+		return RedactString(v.String())
+	}
+	return RedactString(fmt.Sprint(v))
+}
+
+type loggerKey struct{}
+
+// WithLogger returns a context carrying a logger, e.g. with the fields of a
+// badge, for all requests made with it.
+func WithLogger(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, l)
+}
+
+func logger(ctx context.Context) *Logger {
+	if l, ok := ctx.Value(loggerKey{}).(*Logger); ok {
+		return l
+	}
+	return Log
+}
+
+// rateLimitHeaders are the rate limit headers of GitHub and GitLab.
+var rateLimitHeaders = map[string]string{
+	"X-Ratelimit-Limit":     "ratelimit_limit",
+	"X-Ratelimit-Remaining": "ratelimit_remaining",
+	"X-Ratelimit-Reset":     "ratelimit_reset",
+	"Ratelimit-Limit":       "ratelimit_limit",
+	"Ratelimit-Remaining":   "ratelimit_remaining",
+	"Ratelimit-Reset":       "ratelimit_reset",
+	"Retry-After":           "retry_after",
+}
+
+// logHeaders returns the fields of the rate limit headers of a response.
+func logHeaders(header http.Header) []interface{} {
+	var names []string
+	for name := range rateLimitHeaders {
+		if len(header[name]) > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	var kv []interface{}
+	for _, name := range names {
+		kv = append(kv, rateLimitHeaders[name], header[name][0])
+	}
+	return kv
+}
+
+// logRequest logs a request of a provider with the rate limit headers of its
+// response at debug level.
+func logRequest(req *http.Request, resp *http.Response, err error, provider string, attempt int, d time.Duration) {
+	l := logger(req.Context())
+	if !l.Enabled(LevelDebug) {
+		return
+	}
+	u := *req.URL
+	u.User = nil
+	kv := []interface{}{"provider", provider, "method", req.Method, "url", u.String(), "attempt", attempt, "duration", d}
+	if err != nil {
+		l.Debug("request failed", append(kv, "error", err)...)
+		return
+	}
+	kv = append(kv, "status", resp.StatusCode)
+	l.Debug("request", append(kv, logHeaders(resp.Header)...)...)
+}
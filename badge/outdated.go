@@ -0,0 +1,335 @@
+package badge
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/narqo/go-badge"
+)
+
+// Registries are the base URLs used to look up the latest versions of
+// dependencies. They can point to mirrors or proxies like Athens or Verdaccio.
+type Registries struct {
+	Go    string `yaml:"go,omitempty"`
+	NPM   string `yaml:"npm,omitempty"`
+	PyPI  string `yaml:"pypi,omitempty"`
+	Maven string `yaml:"maven,omitempty"`
+}
+
+var Registry = Registries{
+	Go:    "https://proxy.golang.org",
+	NPM:   "https://registry.npmjs.org",
+	PyPI:  "https://pypi.org/pypi",
+	Maven: "https://repo1.maven.org/maven2",
+}
+
+func InitOutdatedBadges() {
+	badges["outdated"] = outdated
+	setCost(CostClone, "outdated")
+}
+
+type dependency struct {
+	Ecosystem string
+	Name      string
+	Version   string
+}
+
+// manifests map the dependency manifests in the root of a project to their
+// parsers.
+var manifests = map[string]func([]byte) ([]dependency, error){
+	"go.mod":           parseGoMod,
+	"package.json":     parsePackageJSON,
+	"requirements.txt": parseRequirements,
+	"pom.xml":          parsePom,
+}
+
+func parseGoMod(content []byte) ([]dependency, error) {
+	var dependencies []dependency
+	inRequire := false
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.Contains(line, "// indirect") {
+			continue
+		}
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		switch {
+		case line == "require (":
+			inRequire = true
+			continue
+		case inRequire && line == ")":
+			inRequire = false
+			continue
+		case strings.HasPrefix(line, "require "):
+			line = strings.TrimPrefix(line, "require ")
+		case !inRequire:
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 2 {
+			dependencies = append(dependencies, dependency{Ecosystem: "go", Name: fields[0], Version: fields[1]})
+		}
+	}
+	return dependencies, nil
+}
+
+func parsePackageJSON(content []byte) ([]dependency, error) {
+	var manifest struct {
+		Dependencies    map[string]string `json:"dependencies"`
+		DevDependencies map[string]string `json:"devDependencies"`
+	}
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return nil, err
+	}
+	var dependencies []dependency
+	for _, deps := range []map[string]string{manifest.Dependencies, manifest.DevDependencies} {
+		for name, version := range deps {
+			dependencies = append(dependencies, dependency{Ecosystem: "npm", Name: name, Version: strings.TrimLeft(version, "^~>=< ")})
+		}
+	}
+	return dependencies, nil
+}
+
+var requirementRe = regexp.MustCompile(`^([A-Za-z0-9_.\-\[\]]+)\s*(?:==|>=|~=)\s*([0-9][^,;\s]*)`)
+
+func parseRequirements(content []byte) ([]dependency, error) {
+	var dependencies []dependency
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		match := requirementRe.FindStringSubmatch(strings.TrimSpace(scanner.Text()))
+		if match == nil {
+			continue
+		}
+		name := match[1]
+		if i := strings.Index(name, "["); i >= 0 {
+			name = name[:i]
+		}
+		dependencies = append(dependencies, dependency{Ecosystem: "pypi", Name: name, Version: match[2]})
+	}
+	return dependencies, nil
+}
+
+func parsePom(content []byte) ([]dependency, error) {
+	var pom struct {
+		Dependencies []struct {
+			GroupID    string `xml:"groupId"`
+			ArtifactID string `xml:"artifactId"`
+			Version    string `xml:"version"`
+		} `xml:"dependencies>dependency"`
+	}
+	if err := xml.Unmarshal(content, &pom); err != nil {
+		return nil, err
+	}
+	var dependencies []dependency
+	for _, dep := range pom.Dependencies {
+		if dep.Version == "" || strings.Contains(dep.Version, "${") {
+			continue
+		}
+		dependencies = append(dependencies, dependency{Ecosystem: "maven", Name: dep.GroupID + ":" + dep.ArtifactID, Version: dep.Version})
+	}
+	return dependencies, nil
+}
+
+// escapeModulePath applies the case encoding of the Go module proxy protocol.
+func escapeModulePath(path string) string {
+	var b strings.Builder
+	for _, r := range path {
+		if r >= 'A' && r <= 'Z' {
+			b.WriteByte('!')
+			r += 'a' - 'A'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func fetchJSON(ctx context.Context, u string, v interface{}) (bool, error) {
+	resp, err := httpGet(ctx, "registry", u)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return false, nil
+	case resp.StatusCode != http.StatusOK:
+		return false, newStatusError("fetching "+u, resp)
+	}
+	return true, json.NewDecoder(resp.Body).Decode(v)
+}
+
+// latestGoVersion returns the latest version of a module. If a module with
+// the next major version suffix exists, its version is returned instead.
+func latestGoVersion(ctx context.Context, module string) (string, error) {
+	var info struct{ Version string }
+	ok, err := fetchJSON(ctx, Registry.Go+"/"+escapeModulePath(module)+"/@latest", &info)
+	if err != nil || !ok {
+		return "", err
+	}
+
+	v, parsed := parseSemver(info.Version)
+	if !parsed || v.Major < 1 {
+		return info.Version, nil
+	}
+	base := module
+	if i := strings.LastIndex(module, "/v"); i >= 0 && strings.Trim(module[i+2:], "0123456789") == "" {
+		base = module[:i]
+	}
+	var next struct{ Version string }
+	ok, err = fetchJSON(ctx, fmt.Sprintf("%s/%s/v%d/@latest", Registry.Go, escapeModulePath(base), v.Major+1), &next)
+	if err == nil && ok {
+		return next.Version, nil
+	}
+	return info.Version, nil
+}
+
+func latestVersion(ctx context.Context, dep dependency) (string, error) {
+	switch dep.Ecosystem {
+	case "go":
+		return latestGoVersion(ctx, dep.Name)
+	case "npm":
+		var info struct{ Version string }
+		_, err := fetchJSON(ctx, Registry.NPM+"/"+url.PathEscape(dep.Name)+"/latest", &info)
+		return info.Version, err
+	case "pypi":
+		var info struct {
+			Info struct{ Version string } `json:"info"`
+		}
+		_, err := fetchJSON(ctx, Registry.PyPI+"/"+url.PathEscape(dep.Name)+"/json", &info)
+		return info.Info.Version, err
+	case "maven":
+		parts := strings.SplitN(dep.Name, ":", 2)
+		u := fmt.Sprintf("%s/%s/%s/maven-metadata.xml", Registry.Maven, strings.ReplaceAll(parts[0], ".", "/"), parts[1])
+		resp, err := httpGet(ctx, "registry", u)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return "", nil
+		}
+		var metadata struct {
+			Release string `xml:"versioning>release"`
+		}
+		err = xml.NewDecoder(resp.Body).Decode(&metadata)
+		return metadata.Release, err
+	}
+	return "", nil
+}
+
+var latestVersionCache sync.Map
+
+func cachedLatestVersion(ctx context.Context, dep dependency) (string, error) {
+	key := dep.Ecosystem + ":" + dep.Name
+	if version, ok := latestVersionCache.Load(key); ok {
+		return version.(string), nil
+	}
+	version, err := latestVersion(ctx, dep)
+	if err != nil {
+		return "", err
+	}
+	latestVersionCache.Store(key, version)
+	return version, nil
+}
+
+// outdated counts the direct dependencies that are behind their latest
+// release by a major or minor version. Only dependencies whose versions could
+// be compared are counted, failed lookups are reported separately.
+func outdated(ctx context.Context, project Project) *Badge {
+	projectPath, err := download(ctx, project)
+	if err != nil {
+		return errorBadge("outdated", project, err)
+	}
+
+	var dependencies []dependency
+	for manifest, parse := range manifests {
+		content, err := ioutil.ReadFile(filepath.Join(projectPath, manifest))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return errorBadge("outdated", project, err)
+		}
+		deps, err := parse(content)
+		if err != nil {
+			return errorBadge("outdated", project, fmt.Errorf("%s: %w", manifest, err))
+		}
+		dependencies = append(dependencies, deps...)
+	}
+	if len(dependencies) == 0 {
+		return nil
+	}
+
+	behind, compared, failed := 0, 0, 0
+	var lookupErr error
+	var report bytes.Buffer
+	for _, dep := range dependencies {
+		current, ok := parseSemver(dep.Version)
+		if !ok {
+			continue
+		}
+		latestVersion, err := cachedLatestVersion(ctx, dep)
+		if ctx.Err() != nil {
+			return errorBadge("outdated", project, ctx.Err())
+		}
+		if err != nil {
+			fmt.Fprintf(&report, "%s %s %s: lookup failed: %s\n", dep.Ecosystem, dep.Name, dep.Version, Redact(err))
+			failed++
+			lookupErr = err
+			continue
+		}
+		latest, ok := parseSemver(latestVersion)
+		if !ok {
+			continue
+		}
+		compared++
+		switch {
+		case latest.Major > current.Major:
+			fmt.Fprintf(&report, "%s %s %s -> %s (major)\n", dep.Ecosystem, dep.Name, dep.Version, latestVersion)
+			behind++
+		case latest.Major == current.Major && latest.Minor > current.Minor:
+			fmt.Fprintf(&report, "%s %s %s -> %s (minor)\n", dep.Ecosystem, dep.Name, dep.Version, latestVersion)
+			behind++
+		}
+	}
+
+	if compared == 0 {
+		if lookupErr != nil {
+			return errorBadge("outdated", project, lookupErr)
+		}
+		return nil
+	}
+
+	outdatedLog := filepath.Join("badges", project.Hoster, project.Name, "outdated.txt")
+	message := fmt.Sprintf("%d of %d", behind, compared)
+	if failed > 0 {
+		message += fmt.Sprintf(", %d failed", failed)
+	}
+	color := badge.ColorBrightgreen
+	switch {
+	case behind > 10:
+		color = badge.ColorRed
+	case behind > 3:
+		color = badge.ColorOrange
+	case behind > 0:
+		color = badge.ColorYellow
+	}
+	b := svgBadge(project.Hoster, project.Name, "outdated", "outdated", message, color, outdatedLog, nil)
+	_ = writeReport(ctx, outdatedLog, report.Bytes())
+	b.Value = behind
+	return b
+}
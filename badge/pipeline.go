@@ -0,0 +1,48 @@
+package badge
+
+import (
+	"time"
+
+	"github.com/narqo/go-badge"
+)
+
+const (
+	PipelinePassed   = "passed"
+	PipelineFailed   = "failed"
+	PipelineRunning  = "running"
+	PipelinePending  = "pending"
+	PipelineCanceled = "canceled"
+	PipelineUnknown  = "unknown"
+)
+
+// Pipeline is the structured result of the CI status badges.
+type Pipeline struct {
+	State    string        `yaml:"state" json:"state"`
+	Duration time.Duration `yaml:"duration,omitempty" json:"duration,omitempty"`
+	URL      string        `yaml:"url,omitempty" json:"url,omitempty"`
+}
+
+func pipelineBadge(name string, project Project, pipeline *Pipeline) *Badge {
+	color := badge.ColorLightgrey
+	switch pipeline.State {
+	case PipelinePassed:
+		color = badge.ColorBrightgreen
+	case PipelineFailed:
+		color = badge.ColorRed
+	case PipelineRunning, PipelinePending:
+		color = badge.ColorYellow
+	}
+
+	text := pipeline.State
+	if pipeline.Duration > 0 {
+		text += " " + pipeline.Duration.Round(time.Second).String()
+	}
+	link := pipeline.URL
+	if link == "" {
+		link = project.URL
+	}
+
+	b := svgBadge(project.Hoster, project.Name, name, "build", text, color, link, nil)
+	b.Value = pipeline
+	return b
+}
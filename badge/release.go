@@ -0,0 +1,317 @@
+package badge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/enfipy/locker"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/narqo/go-badge"
+)
+
+func InitReleaseBadges() {
+	badges["version"] = version
+	badges["release-age"] = releaseAge
+	badges["unreleased"] = unreleased
+	setCost(CostClone, "version", "release-age", "unreleased")
+}
+
+type semver struct {
+	Major, Minor, Patch int
+	Prerelease          string
+}
+
+var semverRe = regexp.MustCompile(`^v?(\d+)\.(\d+)(?:\.(\d+))?(?:-([0-9A-Za-z.\-]+))?(?:\+[0-9A-Za-z.\-]+)?$`)
+
+// parseSemver parses versions like v1.2.3, 1.2.3-rc.1 or v1.2. A missing
+// patch version is treated as zero. Bare numbers like 2020 or build numbers
+// are no versions.
+func parseSemver(s string) (semver, bool) {
+	match := semverRe.FindStringSubmatch(strings.TrimSpace(s))
+	if match == nil {
+		return semver{}, false
+	}
+	v := semver{Prerelease: match[4]}
+	v.Major, _ = strconv.Atoi(match[1])
+	v.Minor, _ = strconv.Atoi(match[2])
+	v.Patch, _ = strconv.Atoi(match[3])
+	return v, true
+}
+
+// less compares two versions by semver precedence.
+func (v semver) less(o semver) bool {
+	switch {
+	case v.Major != o.Major:
+		return v.Major < o.Major
+	case v.Minor != o.Minor:
+		return v.Minor < o.Minor
+	case v.Patch != o.Patch:
+		return v.Patch < o.Patch
+	case v.Prerelease == o.Prerelease:
+		return false
+	case v.Prerelease == "":
+		return false
+	case o.Prerelease == "":
+		return true
+	}
+	return lessPrerelease(v.Prerelease, o.Prerelease)
+}
+
+func lessPrerelease(a, b string) bool {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		if as[i] == bs[i] {
+			continue
+		}
+		an, aErr := strconv.Atoi(as[i])
+		bn, bErr := strconv.Atoi(bs[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			return an < bn
+		case aErr == nil:
+			return true
+		case bErr == nil:
+			return false
+		}
+		return as[i] < bs[i]
+	}
+	return len(as) < len(bs)
+}
+
+// release is a published forge release or a bare tag.
+type release struct {
+	Tag       string
+	Version   semver
+	Date      time.Time
+	Published bool
+}
+
+// releaseResolvers list the published releases with the forge APIs. They
+// return nil for projects of other forges.
+var releaseResolvers []func(context.Context, Project) ([]release, error)
+
+var releaseCache sync.Map
+var releaseLocker = locker.Initialize()
+
+// latestRelease returns the highest semver release of a project. Published
+// releases are preferred over bare tags, pre-releases are ignored unless the
+// project enables them. The project is only cloned if the forge has no
+// published releases. It returns nil if there is no release.
+func latestRelease(ctx context.Context, project Project) (*release, error) {
+	releaseLocker.Lock(project.URL)
+	defer releaseLocker.Unlock(project.URL)
+
+	if r, ok := releaseCache.Load(project.URL); ok {
+		return r.(*release), nil
+	}
+
+	var published []release
+	for _, resolve := range releaseResolvers {
+		releases, err := resolve(ctx, project)
+		if err != nil {
+			return nil, err
+		}
+		published = append(published, releases...)
+	}
+
+	latest := highestRelease(published, project.Prereleases)
+	if latest == nil {
+		repository, err := openClone(ctx, project)
+		if err != nil {
+			return nil, err
+		}
+		tags, err := repositoryTags(repository)
+		if err != nil {
+			return nil, err
+		}
+		latest = highestRelease(tags, project.Prereleases)
+	}
+
+	releaseCache.Store(project.URL, latest)
+	return latest, nil
+}
+
+func openClone(ctx context.Context, project Project) (*git.Repository, error) {
+	projectPath, err := download(ctx, project)
+	if err != nil {
+		return nil, err
+	}
+	return git.PlainOpen(projectPath)
+}
+
+// unreleasedCommits counts the commits of the default branch since a
+// release. It reports false if the tag of the release is not in the
+// repository, e.g. because it was deleted after publishing.
+func unreleasedCommits(ctx context.Context, project Project, r *release) (int, bool, error) {
+	repository, err := openClone(ctx, project)
+	if err != nil {
+		return 0, false, err
+	}
+	count, err := commitsSince(repository, r.Tag)
+	if errors.Is(err, git.ErrTagNotFound) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return count, true, nil
+}
+
+func highestRelease(releases []release, prereleases bool) *release {
+	var highest *release
+	for i, r := range releases {
+		v, ok := parseSemver(r.Tag)
+		if !ok || (v.Prerelease != "" && !prereleases) {
+			continue
+		}
+		releases[i].Version = v
+		if highest == nil || highest.Version.less(v) {
+			highest = &releases[i]
+		}
+	}
+	if highest == nil {
+		return nil
+	}
+	r := *highest
+	return &r
+}
+
+// repositoryTags lists the tags of the clone dated by their commit.
+func repositoryTags(repository *git.Repository) ([]release, error) {
+	tags, err := repository.Tags()
+	if err != nil {
+		return nil, err
+	}
+	var releases []release
+	err = tags.ForEach(func(ref *plumbing.Reference) error {
+		commit, err := tagCommit(repository, ref)
+		if err != nil {
+			return nil // tags of trees or blobs are no releases
+		}
+		releases = append(releases, release{Tag: ref.Name().Short(), Date: commit.Committer.When})
+		return nil
+	})
+	return releases, err
+}
+
+func tagCommit(repository *git.Repository, ref *plumbing.Reference) (*object.Commit, error) {
+	if tag, err := repository.TagObject(ref.Hash()); err == nil {
+		return tag.Commit()
+	}
+	return repository.CommitObject(ref.Hash())
+}
+
+// commitsSince counts the commits of the checked out branch that are not
+// reachable from the tag.
+func commitsSince(repository *git.Repository, tagName string) (int, error) {
+	ref, err := repository.Tag(tagName)
+	if err != nil {
+		return 0, err
+	}
+	tag, err := tagCommit(repository, ref)
+	if err != nil {
+		return 0, err
+	}
+
+	released := map[plumbing.Hash]bool{}
+	err = object.NewCommitPreorderIter(tag, nil, nil).ForEach(func(c *object.Commit) error {
+		released[c.Hash] = true
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	head, err := repository.Head()
+	if err != nil {
+		return 0, err
+	}
+	commits, err := repository.Log(&git.LogOptions{From: head.Hash()})
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	err = commits.ForEach(func(c *object.Commit) error {
+		if !released[c.Hash] {
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+func version(ctx context.Context, project Project) *Badge {
+	r, err := latestRelease(ctx, project)
+	if err != nil {
+		return errorBadge("version", project, err)
+	}
+	if r == nil {
+		return nil
+	}
+	b := svgBadge(project.Hoster, project.Name, "version", "version", r.Tag, badge.ColorBlue, project.URL, nil)
+	b.Value = r.Tag
+	return b
+}
+
+func releaseAge(ctx context.Context, project Project) *Badge {
+	r, err := latestRelease(ctx, project)
+	if err != nil {
+		return errorBadge("release-age", project, err)
+	}
+	if r == nil {
+		return nil
+	}
+
+	days := int(time.Since(r.Date).Hours() / 24)
+	color := badge.ColorOrange
+	switch {
+	case days < 90:
+		color = badge.ColorBrightgreen
+	case days < 180:
+		color = badge.ColorGreen
+	case days < 365:
+		color = badge.ColorYellow
+	}
+	b := svgBadge(project.Hoster, project.Name, "release-age", "last release", fmt.Sprintf("%d days", days), color, project.URL, nil)
+	b.Value = days
+	return b
+}
+
+func unreleased(ctx context.Context, project Project) *Badge {
+	r, err := latestRelease(ctx, project)
+	if err != nil {
+		return errorBadge("unreleased", project, err)
+	}
+	if r == nil {
+		return nil
+	}
+
+	count, ok, err := unreleasedCommits(ctx, project, r)
+	if err != nil {
+		return errorBadge("unreleased", project, err)
+	}
+	if !ok {
+		return svgBadge(project.Hoster, project.Name, "unreleased", "commits since "+r.Tag, "unknown", badge.ColorLightgrey, project.URL, nil)
+	}
+
+	color := badge.ColorOrange
+	switch {
+	case count == 0:
+		color = badge.ColorBrightgreen
+	case count < 10:
+		color = badge.ColorGreen
+	case count < 50:
+		color = badge.ColorYellow
+	}
+	b := svgBadge(project.Hoster, project.Name, "unreleased", "commits since "+r.Tag, fmt.Sprint(count), color, project.URL, nil)
+	b.Value = count
+	return b
+}
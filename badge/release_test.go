@@ -0,0 +1,83 @@
+package badge
+
+import "testing"
+
+func TestParseSemver(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want semver
+		ok   bool
+	}{
+		{"v1.2.3", semver{Major: 1, Minor: 2, Patch: 3}, true},
+		{"1.2.3", semver{Major: 1, Minor: 2, Patch: 3}, true},
+		{"v1.2", semver{Major: 1, Minor: 2}, true},
+		{"1.2.3-rc.1", semver{Major: 1, Minor: 2, Patch: 3, Prerelease: "rc.1"}, true},
+		{"v1.2.3+build.5", semver{Major: 1, Minor: 2, Patch: 3}, true},
+		{"v1.2.3-beta+build", semver{Major: 1, Minor: 2, Patch: 3, Prerelease: "beta"}, true},
+		{" v0.1.0 ", semver{Minor: 1}, true},
+		{"1", semver{}, false},
+		{"v2", semver{}, false},
+		{"2020", semver{}, false},
+		{"20201012", semver{}, false},
+		{"release-1.2.3", semver{}, false},
+		{"v1.2.3.4", semver{}, false},
+		{"latest", semver{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseSemver(tt.tag)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseSemver(%q) = %+v, %v, want %+v, %v", tt.tag, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestSemverLess(t *testing.T) {
+	// Ordered by precedence as in the example of the semver specification.
+	ordered := []string{
+		"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta",
+		"1.0.0-beta.2", "1.0.0-beta.11", "1.0.0-rc.1", "1.0.0", "1.0.1",
+		"1.1.0", "1.10.0", "2.0.0-rc.1", "2.0.0", "v10.0",
+	}
+	for i, a := range ordered {
+		va, _ := parseSemver(a)
+		for j, b := range ordered {
+			vb, _ := parseSemver(b)
+			if got, want := va.less(vb), i < j; got != want {
+				t.Errorf("%s.less(%s) = %v, want %v", a, b, got, want)
+			}
+		}
+	}
+}
+
+func TestHighestRelease(t *testing.T) {
+	tests := []struct {
+		name        string
+		tags        []string
+		prereleases bool
+		want        string
+	}{
+		{"none", nil, false, ""},
+		{"no versions", []string{"latest", "2020", "nightly"}, false, ""},
+		{"semver order", []string{"v1.9.0", "v1.10.0", "v1.2.0"}, false, "v1.10.0"},
+		{"bare numbers", []string{"20201012", "v1.2.3", "2"}, false, "v1.2.3"},
+		{"prerelease ignored", []string{"v1.0.0", "v2.0.0-rc.1"}, false, "v1.0.0"},
+		{"prerelease enabled", []string{"v1.0.0", "v2.0.0-rc.1"}, true, "v2.0.0-rc.1"},
+		{"release over prerelease", []string{"v2.0.0-rc.1", "v2.0.0"}, true, "v2.0.0"},
+		{"only prereleases", []string{"v1.0.0-beta"}, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var releases []release
+			for _, tag := range tt.tags {
+				releases = append(releases, release{Tag: tag})
+			}
+			got := highestRelease(releases, tt.prereleases)
+			switch {
+			case tt.want == "" && got != nil:
+				t.Errorf("highestRelease() = %s, want nil", got.Tag)
+			case tt.want != "" && (got == nil || got.Tag != tt.want):
+				t.Errorf("highestRelease() = %v, want %s", got, tt.want)
+			}
+		})
+	}
+}
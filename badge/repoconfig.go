@@ -0,0 +1,121 @@
+package badge
+
+import (
+	"context"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// RepoConfigFile is read from the root of every project.
+const RepoConfigFile = ".dashboard.yaml"
+
+// fileResolvers read a file of the default branch with the forge APIs. They
+// report whether they handled the project, the content is nil if the file
+// does not exist.
+var fileResolvers []func(context.Context, Project, string) ([]byte, bool, error)
+
+// readRepoFile reads a file of the default branch with the forge APIs and
+// clones projects of other forges. The content is nil if the file does not
+// exist.
+func readRepoFile(ctx context.Context, project Project, name string) ([]byte, error) {
+	for _, resolve := range fileResolvers {
+		content, ok, err := resolve(ctx, project, name)
+		if ok || err != nil {
+			return content, err
+		}
+	}
+
+	projectPath, err := download(ctx, project)
+	if err != nil {
+		return nil, err
+	}
+	content, err := readCloneFile(projectPath, name)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return content, err
+}
+
+// ReadRepoConfig returns the RepoConfigFile of a project or nil if it has
+// none.
+func ReadRepoConfig(ctx context.Context, project Project) ([]byte, error) {
+	return readRepoFile(ctx, project, RepoConfigFile)
+}
+
+// MergeRepoConfig merges the content of a RepoConfigFile into the project.
+// Settings of the central configuration win on conflicts. The URL, hoster
+// and token-from of a project cannot be changed.
+func MergeRepoConfig(project Project, content []byte) (Project, error) {
+	if len(content) == 0 {
+		return project, nil
+	}
+	var repo Project
+	if err := yaml.Unmarshal(content, &repo); err != nil {
+		return project, err
+	}
+	return mergeProject(project, repo), nil
+}
+
+func mergeProject(central, repo Project) Project {
+	for key, value := range repo.Meta {
+		if _, ok := central.Meta[key]; ok {
+			continue
+		}
+		if central.Meta == nil {
+			central.Meta = map[string]string{}
+		}
+		central.Meta[key] = value
+	}
+
+	for _, name := range repo.Enable {
+		if !containsFold(central.Disable, name) && !containsFold(central.Enable, name) {
+			central.Enable = append(central.Enable, name)
+		}
+	}
+	for _, name := range repo.Disable {
+		if !containsFold(central.Enable, name) && !containsFold(central.Disable, name) {
+			central.Disable = append(central.Disable, name)
+		}
+	}
+
+	mergeString(&central.AzureOrganization, repo.AzureOrganization)
+	mergeString(&central.AzureProject, repo.AzureProject)
+	mergeString(&central.AzureDefinitionID, repo.AzureDefinitionID)
+	mergeString(&central.GoImportPath, repo.GoImportPath)
+	mergeString(&central.Workflow, repo.Workflow)
+	mergeString(&central.SARIF, repoPath(repo.SARIF))
+	mergeString(&central.CoverageReport, repoPath(repo.CoverageReport))
+	central.ScanHistory = central.ScanHistory || repo.ScanHistory
+	central.Prereleases = central.Prereleases || repo.Prereleases
+	return central
+}
+
+func mergeString(central *string, repo string) {
+	if *central == "" {
+		*central = repo
+	}
+}
+
+// repoPath returns a path relative to the repository root, or an empty
+// string for URLs and paths outside the repository. Only the central
+// configuration may point reports to other places.
+func repoPath(p string) string {
+	clean := path.Clean(filepath.ToSlash(p))
+	if p == "" || strings.Contains(p, ":") || path.IsAbs(clean) || filepath.IsAbs(p) || clean == ".." || strings.HasPrefix(clean, "../") {
+		return ""
+	}
+	return clean
+}
+
+func containsFold(s []string, e string) bool {
+	for _, a := range s {
+		if strings.EqualFold(a, e) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package badge
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+type reportsKey struct{}
+
+// Reports collects the report files a badge writes next to its SVG, like
+// license.txt or bandit.sarif, so they can be written again when the result
+// of the badge is reused.
+type Reports struct {
+	lock  sync.Mutex
+	files map[string][]byte
+}
+
+// WithReports returns a context that collects the reports written with it.
+func WithReports(ctx context.Context) (context.Context, *Reports) {
+	reports := &Reports{files: map[string][]byte{}}
+	return context.WithValue(ctx, reportsKey{}, reports), reports
+}
+
+// Files returns the collected reports by path.
+func (r *Reports) Files() map[string][]byte {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	files := map[string][]byte{}
+	for path, content := range r.files {
+		files[path] = content
+	}
+	return files
+}
+
+// writeReport writes a report file of a badge and records it in the context.
+func writeReport(ctx context.Context, path string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(path, content, 0666); err != nil {
+		return err
+	}
+	if reports, ok := ctx.Value(reportsKey{}).(*Reports); ok {
+		reports.lock.Lock()
+		reports.files[filepath.ToSlash(path)] = content
+		reports.lock.Unlock()
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package badge
 
 import (
+	"context"
 	"os"
 	"path"
 
@@ -10,10 +11,12 @@ import (
 func InitMissingFileBadges() {
 	badges["readme"] = readme
 	badges["gitignore"] = gitignore
+	setCost(CostClone, "readme", "gitignore")
+	setIncremental("readme", "gitignore")
 }
 
-func readme(project Project) *Badge {
-	projectPath, err := download(project)
+func readme(ctx context.Context, project Project) *Badge {
+	projectPath, err := download(ctx, project)
 	if err != nil {
 		return errorBadge("readme", project, err)
 	}
@@ -27,8 +30,8 @@ func readme(project Project) *Badge {
 	return svgBadge(project.Hoster, project.Name, "readme", "Readme", "exists", badge.ColorBrightgreen, project.URL, nil)
 }
 
-func gitignore(project Project) *Badge {
-	projectPath, err := download(project)
+func gitignore(ctx context.Context, project Project) *Badge {
+	projectPath, err := download(ctx, project)
 	if err != nil {
 		return errorBadge("gitignore", project, err)
 	}
@@ -0,0 +1,197 @@
+package badge
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+)
+
+// RetryPolicy configures the retries of transient errors: 5xx and 429
+// responses, the secondary rate limit of GitHub and network errors. Only GET
+// and HEAD requests are retried, so writes are never sent twice. The backoff doubles with every attempt up to
+// MaxBackoff. A Retry-After header of the response wins over the backoff.
+type RetryPolicy struct {
+	Attempts   int           `yaml:"attempts,omitempty"`
+	Backoff    time.Duration `yaml:"backoff,omitempty"`
+	MaxBackoff time.Duration `yaml:"max-backoff,omitempty"`
+}
+
+// Retries holds the retry policies of the providers github, gitlab, azure,
+// registry, http and git. Unset fields are taken from the policy named
+// default and then from three attempts with a backoff of one to 30 seconds.
+var Retries = map[string]RetryPolicy{}
+
+var defaultRetryPolicy = RetryPolicy{Attempts: 3, Backoff: time.Second, MaxBackoff: 30 * time.Second}
+
+func (p RetryPolicy) merge(defaults RetryPolicy) RetryPolicy {
+	if p.Attempts == 0 {
+		p.Attempts = defaults.Attempts
+	}
+	if p.Backoff == 0 {
+		p.Backoff = defaults.Backoff
+	}
+	if p.MaxBackoff == 0 {
+		p.MaxBackoff = defaults.MaxBackoff
+	}
+	return p
+}
+
+func retryPolicy(provider string) RetryPolicy {
+	policy := Retries[provider].merge(Retries["default"]).merge(defaultRetryPolicy)
+	if policy.Attempts < 1 {
+		policy.Attempts = 1
+	}
+	return policy
+}
+
+// backoff returns the wait before the next attempt with up to 50% jitter.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	wait := p.Backoff << uint(attempt-1)
+	if wait > p.MaxBackoff || wait <= 0 {
+		wait = p.MaxBackoff
+	}
+	return wait/2 + time.Duration(rand.Int63n(int64(wait/2)+1))
+}
+
+type retryCounterKey struct{}
+
+// WithRetryCounter returns a context that counts the retries of all requests
+// and clones made with it.
+func WithRetryCounter(ctx context.Context) (context.Context, *int32) {
+	var count int32
+	return context.WithValue(ctx, retryCounterKey{}, &count), &count
+}
+
+func countRetry(ctx context.Context) {
+	if count, ok := ctx.Value(retryCounterKey{}).(*int32); ok {
+		atomic.AddInt32(count, 1)
+	}
+}
+
+// sleep waits for d or until the context is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func retryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status >= 500
+}
+
+// rateLimited reports whether a response is a rate limit of GitHub, which
+// answers with 403 and a Retry-After header or no remaining requests.
+func rateLimited(resp *http.Response) bool {
+	return resp.StatusCode == http.StatusForbidden &&
+		(resp.Header.Get("Retry-After") != "" || resp.Header.Get("X-RateLimit-Remaining") == "0")
+}
+
+func retryableResponse(resp *http.Response) bool {
+	return retryableStatus(resp.StatusCode) || rateLimited(resp)
+}
+
+// idempotent reports whether a request can be sent again without changing
+// anything twice.
+func idempotent(req *http.Request) bool {
+	return req.Method == http.MethodGet || req.Method == http.MethodHead
+}
+
+// retryAfter parses a Retry-After header given in seconds or as a date, or
+// the X-RateLimit-Reset header if no requests are remaining.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if header := resp.Header.Get("Retry-After"); header != "" {
+		if seconds, err := strconv.Atoi(header); err == nil {
+			return time.Duration(seconds) * time.Second, true
+		}
+		if date, err := http.ParseTime(header); err == nil {
+			return time.Until(date), true
+		}
+		return 0, false
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			return time.Until(time.Unix(reset, 0)), true
+		}
+	}
+	return 0, false
+}
+
+// retryTransport retries requests of a provider on transient errors.
+type retryTransport struct {
+	provider string
+	base     http.RoundTripper
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	policy := retryPolicy(t.provider)
+	for attempt := 1; ; attempt++ {
+		start := time.Now()
+		resp, err := t.base.RoundTrip(req)
+		logRequest(req, resp, err, t.provider, attempt, time.Since(start))
+		ctx := req.Context()
+		switch {
+		case attempt >= policy.Attempts || ctx.Err() != nil || !idempotent(req):
+			return resp, err
+		case err == nil && !retryableResponse(resp):
+			return resp, nil
+		case req.Body != nil && req.GetBody == nil:
+			return resp, err // the body cannot be sent again
+		}
+
+		wait := policy.backoff(attempt)
+		if err == nil {
+			if after, ok := retryAfter(resp); ok {
+				wait = after
+				if wait < 0 {
+					wait = 0
+				}
+			}
+			// Waiting beyond the deadline only hides the response.
+			if deadline, ok := ctx.Deadline(); ok && time.Now().Add(wait).After(deadline) {
+				return resp, nil
+			}
+			_, _ = io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(ctx)
+			req.Body = body
+		}
+		countRetry(ctx)
+	}
+}
+
+// transientCloneError reports whether a clone failed because of the network
+// or a server error.
+func transientCloneError(err error) bool {
+	var netErr net.Error
+	var httpErr *githttp.Err
+	switch {
+	case errors.As(err, &netErr):
+		return true
+	case errors.As(err, &httpErr):
+		return retryableStatus(httpErr.Response.StatusCode)
+	}
+	return errors.Is(err, io.ErrUnexpectedEOF)
+}
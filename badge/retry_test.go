@@ -0,0 +1,116 @@
+package badge
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestRetryTransport(t *testing.T) {
+	Retries["test"] = RetryPolicy{Attempts: 3, Backoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond}
+	defer delete(Retries, "test")
+
+	tests := []struct {
+		name     string
+		method   string
+		statuses []int
+		headers  map[string]string
+		attempts int
+		status   int
+	}{
+		{"success", http.MethodGet, []int{200}, nil, 1, 200},
+		{"server error then success", http.MethodGet, []int{503, 200}, nil, 2, 200},
+		{"server error until attempts", http.MethodGet, []int{500, 500, 500, 500}, nil, 3, 500},
+		{"too many requests", http.MethodGet, []int{429, 200}, map[string]string{"Retry-After": "0"}, 2, 200},
+		{"secondary rate limit", http.MethodGet, []int{403, 200}, map[string]string{"Retry-After": "0"}, 2, 200},
+		{"no remaining requests", http.MethodGet, []int{403, 200}, map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": "0"}, 2, 200},
+		{"forbidden", http.MethodGet, []int{403, 200}, nil, 1, 403},
+		{"not found", http.MethodGet, []int{404, 200}, nil, 1, 404},
+		{"head", http.MethodHead, []int{502, 200}, nil, 2, 200},
+		{"post is not retried", http.MethodPost, []int{503, 200}, nil, 1, 503},
+		{"delete is not retried", http.MethodDelete, []int{503, 200}, nil, 1, 503},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				status := tt.statuses[attempts]
+				attempts++
+				if status != 200 {
+					for key, value := range tt.headers {
+						w.Header().Set(key, value)
+					}
+				}
+				w.WriteHeader(status)
+			}))
+			defer server.Close()
+
+			req, err := http.NewRequest(tt.method, server.URL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := (&retryTransport{provider: "test", base: http.DefaultTransport}).RoundTrip(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.status || attempts != tt.attempts {
+				t.Errorf("got status %d after %d attempts, want %d after %d", resp.StatusCode, attempts, tt.status, tt.attempts)
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    time.Duration
+		ok      bool
+	}{
+		{"none", nil, 0, false},
+		{"seconds", map[string]string{"Retry-After": "120"}, 2 * time.Minute, true},
+		{"date", map[string]string{"Retry-After": now.Add(time.Hour).UTC().Format(http.TimeFormat)}, time.Hour, true},
+		{"invalid", map[string]string{"Retry-After": "soon"}, 0, false},
+		{"rate limit reset", map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": strconv.FormatInt(now.Add(time.Minute).Unix(), 10)}, time.Minute, true},
+		{"remaining requests", map[string]string{"X-RateLimit-Remaining": "10", "X-RateLimit-Reset": strconv.FormatInt(now.Add(time.Minute).Unix(), 10)}, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{Header: http.Header{}}
+			for key, value := range tt.headers {
+				resp.Header.Set(key, value)
+			}
+			got, ok := retryAfter(resp)
+			if ok != tt.ok || got < tt.want-2*time.Second || got > tt.want+time.Second {
+				t.Errorf("retryAfter() = %v, %v, want %v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	policy := RetryPolicy{Attempts: 10, Backoff: time.Second, MaxBackoff: 30 * time.Second}
+	tests := []struct {
+		attempt int
+		max     time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{5, 16 * time.Second},
+		{6, 30 * time.Second},
+		{70, 30 * time.Second},
+	}
+	for _, tt := range tests {
+		for i := 0; i < 100; i++ {
+			got := policy.backoff(tt.attempt)
+			if got < tt.max/2 || got > tt.max {
+				t.Fatalf("backoff(%d) = %v, want between %v and %v", tt.attempt, got, tt.max/2, tt.max)
+			}
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package badge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/narqo/go-badge"
+)
+
+const sarifSchema = "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json"
+
+// sarifLog is a SARIF 2.1.0 log reduced to the fields the dashboard reads and writes.
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema,omitempty"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string `json:"name"`
+	InformationURI string `json:"informationUri,omitempty"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId,omitempty"`
+	Level     string          `json:"level,omitempty"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine,omitempty"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+func InitSARIFBadges() {
+	badges["sarif"] = sarif
+	setCost(CostClone, "sarif")
+}
+
+func newSarifLog(tool, informationURI string, results []sarifResult) *sarifLog {
+	if results == nil {
+		results = []sarifResult{}
+	}
+	return &sarifLog{
+		Version: "2.1.0",
+		Schema:  sarifSchema,
+		Runs: []sarifRun{{
+			Tool:    sarifTool{Driver: sarifDriver{Name: tool, InformationURI: informationURI}},
+			Results: results,
+		}},
+	}
+}
+
+// writeSarif stores the log next to the badge of the same name.
+func writeSarif(ctx context.Context, project Project, name string, log *sarifLog) (string, error) {
+	b, err := json.MarshalIndent(log, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	sarifPath := filepath.Join("badges", project.Hoster, project.Name, name+".sarif")
+	return sarifPath, writeReport(ctx, sarifPath, b)
+}
+
+// levels counts the results of all runs by level. Results without a level
+// count as warnings, as defined by the SARIF specification.
+func (l *sarifLog) levels() map[string]int {
+	count := map[string]int{}
+	for _, run := range l.Runs {
+		for _, result := range run.Results {
+			level := result.Level
+			if level == "" {
+				level = "warning"
+			}
+			count[level]++
+		}
+	}
+	return count
+}
+
+func newSarifResult(ruleID, level, message, file string, line, column int) sarifResult {
+	result := sarifResult{RuleID: ruleID, Level: level, Message: sarifMessage{Text: message}}
+	if file != "" {
+		location := sarifLocation{PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(file)}}}
+		if line > 0 {
+			location.PhysicalLocation.Region = &sarifRegion{StartLine: line, StartColumn: column}
+		}
+		result.Locations = []sarifLocation{location}
+	}
+	return result
+}
+
+var locationRe = regexp.MustCompile(`^(\S+?):(\d+):(?:(\d+):)?\s*(.*)$`)
+var ruleRe = regexp.MustCompile(`^([A-Z]+[0-9]+)\s+(.*)$`)
+
+// lineResults converts "file:line[:column]: message" lines as printed by most
+// linters into SARIF results. Lines in other formats are skipped.
+func lineResults(output []byte, projectPath, level string) []sarifResult {
+	var results []sarifResult
+	for _, line := range strings.Split(string(output), "\n") {
+		match := locationRe.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			continue
+		}
+		file := match[1]
+		if rel, err := filepath.Rel(projectPath, file); err == nil && !strings.HasPrefix(rel, "..") {
+			file = rel
+		}
+		lineNumber, _ := strconv.Atoi(match[2])
+		column, _ := strconv.Atoi(match[3])
+		ruleID, message := "", match[4]
+		if rule := ruleRe.FindStringSubmatch(message); rule != nil {
+			ruleID, message = rule[1], rule[2]
+		}
+		results = append(results, newSarifResult(ruleID, level, message, file, lineNumber, column))
+	}
+	return results
+}
+
+// textResults converts every non-empty output line into a SARIF result.
+func textResults(output []byte, level string) []sarifResult {
+	var results []sarifResult
+	for _, line := range strings.Split(string(output), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		results = append(results, newSarifResult("", level, line, "", 0, 0))
+	}
+	return results
+}
+
+func readSarif(ctx context.Context, project Project) ([]byte, error) {
+	if strings.HasPrefix(project.SARIF, "http") {
+		resp, err := httpGet(ctx, "http", project.SARIF)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, newStatusError("fetching "+project.SARIF, resp)
+		}
+		return ioutil.ReadAll(resp.Body)
+	}
+
+	projectPath, err := download(ctx, project)
+	if err != nil {
+		return nil, err
+	}
+	return readCloneFile(projectPath, project.SARIF)
+}
+
+func sarif(ctx context.Context, project Project) *Badge {
+	if project.SARIF == "" {
+		return nil
+	}
+
+	data, err := readSarif(ctx, project)
+	if err != nil {
+		return errorBadge("sarif", project, err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(data, &log); err != nil {
+		return errorBadge("sarif", project, err)
+	}
+
+	link := project.URL
+	if sarifPath, err := writeSarif(ctx, project, "sarif", &log); err == nil {
+		link = sarifPath
+	}
+
+	count := log.levels()
+	var parts []string
+	for _, level := range []string{"error", "warning", "note"} {
+		if count[level] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", count[level], level))
+		}
+	}
+
+	text, color := "clean", badge.ColorBrightgreen
+	switch {
+	case count["error"] > 0:
+		color = badge.ColorRed
+	case count["warning"] > 0:
+		color = badge.ColorYellow
+	case count["note"] > 0:
+		color = badge.ColorGreen
+	}
+	if len(parts) > 0 {
+		text = strings.Join(parts, " ")
+	}
+	b := svgBadge(project.Hoster, project.Name, "sarif", "sarif", text, color, link, nil)
+	b.Value = count["error"] + count["warning"] + count["note"]
+	return b
+}
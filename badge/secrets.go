@@ -0,0 +1,329 @@
+package badge
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/narqo/go-badge"
+	"gopkg.in/yaml.v2"
+)
+
+// SecretRule matches a single kind of secret. If the regex contains a capture
+// group, the first group is treated as the secret, otherwise the whole match.
+// Matches with a Shannon entropy below Entropy are ignored.
+type SecretRule struct {
+	Name    string  `yaml:"name"`
+	Regex   string  `yaml:"regex"`
+	Entropy float64 `yaml:"entropy,omitempty"`
+
+	re *regexp.Regexp
+}
+
+// SecretRules is the rule set of the secrets badge. Paths matching one of the
+// Ignore regexes are not scanned.
+type SecretRules struct {
+	Rules  []SecretRule `yaml:"rules"`
+	Ignore []string     `yaml:"ignore,omitempty"`
+
+	ignore []*regexp.Regexp
+}
+
+var defaultSecretRules = SecretRules{
+	Rules: []SecretRule{
+		{Name: "private key", Regex: `-----BEGIN (?:RSA |DSA |EC |OPENSSH |PGP |ENCRYPTED )?PRIVATE KEY`},
+		{Name: "aws access key", Regex: `\b((?:AKIA|ASIA)[0-9A-Z]{16})\b`},
+		{Name: "github token", Regex: `\b(gh[pousr]_[0-9A-Za-z]{36})\b`},
+		{Name: "gitlab token", Regex: `\b(glpat-[0-9A-Za-z_\-]{20})\b`},
+		{Name: "slack token", Regex: `\b(xox[abposr]-[0-9A-Za-z\-]{10,72})\b`},
+		{Name: "google api key", Regex: `\b(AIza[0-9A-Za-z_\-]{35})\b`},
+		{Name: "generic secret", Regex: `(?i)(?:password|passwd|secret|token|api_?key)["']?\s*[:=]\s*["']([^"'\s]{8,})["']`, Entropy: 3.5},
+	},
+	Ignore: []string{`(^|/)vendor/`, `(^|/)node_modules/`, `\.(?:png|jpe?g|gif|ico|pdf|zip|gz)$`},
+}
+
+var secretRules = defaultSecretRules
+
+// SecretLocations publishes the files, lines and commits of found secrets in
+// the reports of the secrets badge. By default the reports only count the
+// findings of every rule, so they do not point to the secrets.
+var SecretLocations = false
+
+// LoadSecretRules replaces the built-in rule set with the rules from a YAML file.
+func LoadSecretRules(path string) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var rules SecretRules
+	if err := yaml.Unmarshal(b, &rules); err != nil {
+		return err
+	}
+	if err := rules.compile(); err != nil {
+		return err
+	}
+	secretRules = rules
+	return nil
+}
+
+func (r *SecretRules) compile() error {
+	for i, rule := range r.Rules {
+		re, err := regexp.Compile(rule.Regex)
+		if err != nil {
+			return fmt.Errorf("secret rule %s: %w", rule.Name, err)
+		}
+		r.Rules[i].re = re
+	}
+	r.ignore = nil
+	for _, ignore := range r.Ignore {
+		re, err := regexp.Compile(ignore)
+		if err != nil {
+			return fmt.Errorf("secret ignore %s: %w", ignore, err)
+		}
+		r.ignore = append(r.ignore, re)
+	}
+	return nil
+}
+
+func (r *SecretRules) ignored(path string) bool {
+	for _, re := range r.ignore {
+		if re.MatchString(path) {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	if err := defaultSecretRules.compile(); err != nil {
+		panic(err)
+	}
+	secretRules = defaultSecretRules
+}
+
+type secretFinding struct {
+	Rule   string
+	Path   string
+	Line   int
+	Commit string
+	Secret string
+}
+
+func (f secretFinding) String() string {
+	location := fmt.Sprintf("%s:%d", f.Path, f.Line)
+	if f.Commit != "" {
+		location = fmt.Sprintf("%s (commit %s)", location, f.Commit[:7])
+	}
+	return fmt.Sprintf("%s: %s %s", location, f.Rule, redactSecret(f.Secret))
+}
+
+// secretsReport lists the findings with their locations if SecretLocations
+// is set, otherwise the number of findings and the fingerprints per rule.
+func secretsReport(findings []secretFinding) []byte {
+	var report bytes.Buffer
+	if SecretLocations {
+		for _, finding := range findings {
+			report.WriteString(finding.String() + "\n")
+		}
+		return report.Bytes()
+	}
+
+	counts := map[string]int{}
+	fingerprints := map[string]map[string]bool{}
+	for _, finding := range findings {
+		counts[finding.Rule]++
+		if fingerprints[finding.Rule] == nil {
+			fingerprints[finding.Rule] = map[string]bool{}
+		}
+		fingerprints[finding.Rule][redactSecret(finding.Secret)] = true
+	}
+	var rules []string
+	for rule := range counts {
+		rules = append(rules, rule)
+	}
+	sort.Strings(rules)
+	for _, rule := range rules {
+		var list []string
+		for fingerprint := range fingerprints[rule] {
+			list = append(list, fingerprint)
+		}
+		sort.Strings(list)
+		fmt.Fprintf(&report, "%s: %d findings (%s)\n", rule, counts[rule], strings.Join(list, ", "))
+	}
+	return report.Bytes()
+}
+
+// redactSecret replaces a secret by a short fingerprint, so findings can be
+// told apart and matched with a known secret without publishing any part of
+// it.
+func redactSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return "sha256:" + hex.EncodeToString(sum[:4])
+}
+
+func entropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	count := map[rune]float64{}
+	for _, r := range s {
+		count[r]++
+	}
+	var e float64
+	length := float64(len([]rune(s)))
+	for _, c := range count {
+		p := c / length
+		e -= p * math.Log2(p)
+	}
+	return e
+}
+
+func isBinary(content []byte) bool {
+	if len(content) > 8000 {
+		content = content[:8000]
+	}
+	return bytes.IndexByte(content, 0) != -1
+}
+
+func scanContent(path, commit string, content []byte) []secretFinding {
+	if isBinary(content) {
+		return nil
+	}
+
+	var findings []secretFinding
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		for _, rule := range secretRules.Rules {
+			for _, match := range rule.re.FindAllStringSubmatch(scanner.Text(), -1) {
+				secret := match[0]
+				if len(match) > 1 {
+					secret = match[1]
+				}
+				if rule.Entropy > 0 && entropy(secret) < rule.Entropy {
+					continue
+				}
+				findings = append(findings, secretFinding{Rule: rule.Name, Path: path, Line: line, Commit: commit, Secret: secret})
+			}
+		}
+	}
+	return findings
+}
+
+func scanWorktree(projectPath string) ([]secretFinding, error) {
+	var findings []secretFinding
+	err := filepath.Walk(projectPath, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(projectPath, p)
+		rel = filepath.ToSlash(rel)
+		if info.IsDir() {
+			if info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() || info.Size() > 1024*1024 || secretRules.ignored(rel) {
+			return nil
+		}
+		content, err := ioutil.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		findings = append(findings, scanContent(rel, "", content)...)
+		return nil
+	})
+	return findings, err
+}
+
+// scanHistory scans every blob reachable from any ref once and attributes
+// findings to the newest commit containing the blob.
+func scanHistory(ctx context.Context, projectPath string) ([]secretFinding, error) {
+	repository, err := git.PlainOpen(projectPath)
+	if err != nil {
+		return nil, err
+	}
+	commits, err := repository.Log(&git.LogOptions{All: true})
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []secretFinding
+	seen := map[plumbing.Hash]bool{}
+	err = commits.ForEach(func(commit *object.Commit) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		files, err := commit.Files()
+		if err != nil {
+			return err
+		}
+		return files.ForEach(func(file *object.File) error {
+			if seen[file.Hash] || file.Size > 1024*1024 || secretRules.ignored(file.Name) {
+				return nil
+			}
+			seen[file.Hash] = true
+			content, err := file.Contents()
+			if err != nil {
+				return err
+			}
+			findings = append(findings, scanContent(file.Name, commit.Hash.String(), []byte(content))...)
+			return nil
+		})
+	})
+	return findings, err
+}
+
+func secrets(ctx context.Context, project Project) *Badge {
+	projectPath, err := download(ctx, project)
+	if err != nil {
+		return errorBadge("secrets", project, err)
+	}
+
+	var findings []secretFinding
+	if project.ScanHistory {
+		findings, err = scanHistory(ctx, projectPath)
+	} else {
+		findings, err = scanWorktree(projectPath)
+	}
+	if err != nil {
+		return errorBadge("secrets", project, err)
+	}
+
+	var results []sarifResult
+	for _, finding := range findings {
+		path, line := "", 0
+		if SecretLocations {
+			path, line = finding.Path, finding.Line
+		}
+		results = append(results, newSarifResult(finding.Rule, "error", finding.Rule+" "+redactSecret(finding.Secret), path, line, 0))
+	}
+	_, _ = writeSarif(ctx, project, "secrets", newSarifLog("secrets", "", results))
+
+	secretsLog := filepath.Join("badges", project.Hoster, project.Name, "secrets.txt")
+	if len(findings) > 0 {
+		b := svgBadge(project.Hoster, project.Name, "secrets", "secrets", fmt.Sprintf("%d findings", len(findings)), badge.ColorRed, secretsLog, nil)
+		_ = writeReport(ctx, secretsLog, secretsReport(findings))
+		b.Value = len(findings)
+		return b
+	}
+	b := svgBadge(project.Hoster, project.Name, "secrets", "secrets", "none", badge.ColorBrightgreen, project.URL, nil)
+	b.Value = 0
+	return b
+}
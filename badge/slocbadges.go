@@ -0,0 +1,330 @@
+package badge
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/enfipy/locker"
+	"github.com/go-git/go-billy/v5/osfs"
+	ignore "github.com/go-git/go-git/v5/plumbing/format/gitignore"
+	"github.com/narqo/go-badge"
+)
+
+func InitSlocBadges() {
+	badges["sloc"] = sloc
+	badges["languages"] = languages
+	setCost(CostClone, "sloc", "languages")
+	setIncremental("sloc", "languages")
+}
+
+type language struct {
+	Name       string
+	Color      string
+	Line       []string
+	BlockStart string
+	BlockEnd   string
+}
+
+var (
+	langC          = language{Name: "C", Color: "#555555", Line: []string{"//"}, BlockStart: "/*", BlockEnd: "*/"}
+	langCpp        = language{Name: "C++", Color: "#f34b7d", Line: []string{"//"}, BlockStart: "/*", BlockEnd: "*/"}
+	langCSharp     = language{Name: "C#", Color: "#178600", Line: []string{"//"}, BlockStart: "/*", BlockEnd: "*/"}
+	langCSS        = language{Name: "CSS", Color: "#563d7c", BlockStart: "/*", BlockEnd: "*/"}
+	langGo         = language{Name: "Go", Color: "#00add8", Line: []string{"//"}, BlockStart: "/*", BlockEnd: "*/"}
+	langHTML       = language{Name: "HTML", Color: "#e34c26", BlockStart: "<!--", BlockEnd: "-->"}
+	langJava       = language{Name: "Java", Color: "#b07219", Line: []string{"//"}, BlockStart: "/*", BlockEnd: "*/"}
+	langJavaScript = language{Name: "JavaScript", Color: "#f1e05a", Line: []string{"//"}, BlockStart: "/*", BlockEnd: "*/"}
+	langKotlin     = language{Name: "Kotlin", Color: "#a97bff", Line: []string{"//"}, BlockStart: "/*", BlockEnd: "*/"}
+	langMarkdown   = language{Name: "Markdown", Color: "#083fa1"}
+	langPerl       = language{Name: "Perl", Color: "#0298c3", Line: []string{"#"}}
+	langPHP        = language{Name: "PHP", Color: "#4f5d95", Line: []string{"//", "#"}, BlockStart: "/*", BlockEnd: "*/"}
+	langPython     = language{Name: "Python", Color: "#3572a5", Line: []string{"#"}}
+	langRuby       = language{Name: "Ruby", Color: "#701516", Line: []string{"#"}}
+	langRust       = language{Name: "Rust", Color: "#dea584", Line: []string{"//"}, BlockStart: "/*", BlockEnd: "*/"}
+	langShell      = language{Name: "Shell", Color: "#89e051", Line: []string{"#"}}
+	langSQL        = language{Name: "SQL", Color: "#e38c00", Line: []string{"--"}, BlockStart: "/*", BlockEnd: "*/"}
+	langTypeScript = language{Name: "TypeScript", Color: "#2b7489", Line: []string{"//"}, BlockStart: "/*", BlockEnd: "*/"}
+	langYAML       = language{Name: "YAML", Color: "#cb171e", Line: []string{"#"}}
+)
+
+var extensions = map[string]language{
+	".c": langC, ".h": langC,
+	".cc": langCpp, ".cpp": langCpp, ".cxx": langCpp, ".hpp": langCpp,
+	".cs":  langCSharp,
+	".css": langCSS, ".scss": langCSS,
+	".go":   langGo,
+	".html": langHTML, ".htm": langHTML,
+	".java": langJava,
+	".js":   langJavaScript, ".jsx": langJavaScript, ".mjs": langJavaScript,
+	".kt": langKotlin,
+	".md": langMarkdown,
+	".pl": langPerl, ".pm": langPerl,
+	".php": langPHP,
+	".py":  langPython,
+	".rb":  langRuby,
+	".rs":  langRust,
+	".sh":  langShell, ".bash": langShell,
+	".sql": langSQL,
+	".ts":  langTypeScript, ".tsx": langTypeScript,
+	".yml": langYAML, ".yaml": langYAML,
+}
+
+var interpreters = map[string]language{
+	"bash": langShell, "sh": langShell, "zsh": langShell,
+	"node":   langJavaScript,
+	"perl":   langPerl,
+	"php":    langPHP,
+	"python": langPython, "python2": langPython, "python3": langPython,
+	"ruby": langRuby,
+}
+
+var vendoredDirs = map[string]bool{
+	".git": true, "vendor": true, "node_modules": true, "third_party": true, "bower_components": true,
+}
+
+// classify returns the language of a file by its extension or, for files
+// without a known extension, by the interpreter in its shebang line.
+func classify(name string, content []byte) (language, bool) {
+	if lang, ok := extensions[strings.ToLower(filepath.Ext(name))]; ok {
+		return lang, true
+	}
+	if !bytes.HasPrefix(content, []byte("#!")) {
+		return language{}, false
+	}
+	shebang := strings.Fields(strings.SplitN(string(content[2:]), "\n", 2)[0])
+	if len(shebang) == 0 {
+		return language{}, false
+	}
+	interpreter := filepath.Base(shebang[0])
+	if interpreter == "env" && len(shebang) > 1 {
+		interpreter = shebang[1]
+	}
+	lang, ok := interpreters[interpreter]
+	return lang, ok
+}
+
+func isGenerated(name string, content []byte) bool {
+	if strings.HasSuffix(name, ".min.js") || strings.HasSuffix(name, ".min.css") {
+		return true
+	}
+	head := content
+	if len(head) > 1024 {
+		head = head[:1024]
+	}
+	return (bytes.Contains(head, []byte("Code generated")) && bytes.Contains(head, []byte("DO NOT EDIT"))) ||
+		bytes.Contains(head, []byte("@generated"))
+}
+
+type lineCount struct {
+	Language string
+	Color    string
+	Files    int
+	Code     int
+	Comment  int
+	Blank    int
+}
+
+func countLines(lang language, content []byte) lineCount {
+	count := lineCount{Language: lang.Name, Color: lang.Color, Files: 1}
+	inBlock := false
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case inBlock:
+			count.Comment++
+			if strings.Contains(line, lang.BlockEnd) {
+				inBlock = false
+			}
+		case line == "":
+			count.Blank++
+		case lang.BlockStart != "" && strings.HasPrefix(line, lang.BlockStart):
+			count.Comment++
+			inBlock = !strings.Contains(line[len(lang.BlockStart):], lang.BlockEnd)
+		case hasAnyPrefix(line, lang.Line):
+			count.Comment++
+		default:
+			count.Code++
+		}
+	}
+	return count
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+var slocCache sync.Map
+var slocLocker = locker.Initialize()
+
+// countProject counts the lines of all source files of a project, sorted by
+// lines of code.
+func countProject(ctx context.Context, project Project) ([]lineCount, error) {
+	slocLocker.Lock(project.URL)
+	defer slocLocker.Unlock(project.URL)
+
+	if counts, ok := slocCache.Load(project.URL); ok {
+		return counts.([]lineCount), nil
+	}
+
+	projectPath, err := download(ctx, project)
+	if err != nil {
+		return nil, err
+	}
+
+	patterns, err := ignore.ReadPatterns(osfs.New(projectPath), nil)
+	if err != nil {
+		return nil, err
+	}
+	ignored := ignore.NewMatcher(patterns)
+
+	byLanguage := map[string]*lineCount{}
+	err = filepath.Walk(projectPath, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		rel, _ := filepath.Rel(projectPath, p)
+		parts := strings.Split(filepath.ToSlash(rel), "/")
+		if info.IsDir() {
+			if rel != "." && (vendoredDirs[info.Name()] || ignored.Match(parts, true)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() || ignored.Match(parts, false) {
+			return nil
+		}
+
+		content, err := ioutil.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		lang, ok := classify(info.Name(), content)
+		if !ok || isBinary(content) || isGenerated(info.Name(), content) {
+			return nil
+		}
+
+		count := countLines(lang, content)
+		if total, ok := byLanguage[lang.Name]; ok {
+			total.Files++
+			total.Code += count.Code
+			total.Comment += count.Comment
+			total.Blank += count.Blank
+		} else {
+			byLanguage[lang.Name] = &count
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var counts []lineCount
+	for _, count := range byLanguage {
+		counts = append(counts, *count)
+	}
+	sort.Slice(counts, func(i, j int) bool { return counts[i].Code > counts[j].Code })
+
+	slocCache.Store(project.URL, counts)
+	return counts, nil
+}
+
+func shortNumber(n int) string {
+	switch {
+	case n >= 1000000:
+		return fmt.Sprintf("%.1fM", float64(n)/1000000)
+	case n >= 1000:
+		return fmt.Sprintf("%.1fk", float64(n)/1000)
+	}
+	return fmt.Sprint(n)
+}
+
+func sloc(ctx context.Context, project Project) *Badge {
+	counts, err := countProject(ctx, project)
+	if err != nil {
+		return errorBadge("sloc", project, err)
+	}
+
+	var total lineCount
+	var report bytes.Buffer
+	fmt.Fprintf(&report, "%-12s %8s %8s %8s %8s\n", "language", "files", "code", "comment", "blank")
+	for _, count := range counts {
+		fmt.Fprintf(&report, "%-12s %8d %8d %8d %8d\n", count.Language, count.Files, count.Code, count.Comment, count.Blank)
+		total.Files += count.Files
+		total.Code += count.Code
+		total.Comment += count.Comment
+		total.Blank += count.Blank
+	}
+	fmt.Fprintf(&report, "%-12s %8d %8d %8d %8d\n", "total", total.Files, total.Code, total.Comment, total.Blank)
+
+	slocLog := filepath.Join("badges", project.Hoster, project.Name, "sloc.txt")
+	b := svgBadge(project.Hoster, project.Name, "sloc", "lines of code", shortNumber(total.Code), badge.ColorBlue, slocLog, nil)
+	_ = writeReport(ctx, slocLog, report.Bytes())
+	b.Value = total.Code
+	return b
+}
+
+// languages renders the share of the top languages as a stacked bar.
+func languages(ctx context.Context, project Project) *Badge {
+	counts, err := countProject(ctx, project)
+	if err != nil {
+		return errorBadge("languages", project, err)
+	}
+
+	total := 0
+	for _, count := range counts {
+		total += count.Code
+	}
+	if total == 0 {
+		return nil
+	}
+
+	const width, barHeight, top = 300, 8, 5
+	if len(counts) > top {
+		other := lineCount{Language: "Other", Color: "#ededed"}
+		for _, count := range counts[top:] {
+			other.Code += count.Code
+		}
+		counts = append(counts[:top:top], other)
+	}
+
+	var svg bytes.Buffer
+	fmt.Fprintf(&svg, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-family="Verdana,sans-serif" font-size="10">`, width, barHeight+14*len(counts)+4)
+	x := 0.0
+	for i, count := range counts {
+		share := float64(count.Code) / float64(total)
+		fmt.Fprintf(&svg, `<rect x="%.1f" y="0" width="%.1f" height="%d" fill="%s"/>`, x, share*width, barHeight, count.Color)
+		fmt.Fprintf(&svg, `<rect x="0" y="%d" width="8" height="8" fill="%s"/><text x="12" y="%d">%s %.1f%%</text>`,
+			barHeight+4+14*i, count.Color, barHeight+12+14*i, count.Language, share*100)
+		x += share * width
+	}
+	svg.WriteString(`</svg>`)
+
+	err = writeReport(ctx, filepath.Join("badges", project.Hoster, project.Name, "languages.svg"), svg.Bytes())
+	if err != nil {
+		return errorBadge("languages", project, err)
+	}
+
+	return &Badge{
+		URL:   fmt.Sprintf("badges/%s/%s/languages.svg", project.Hoster, project.Name),
+		Link:  project.URL,
+		Title: "languages",
+	}
+}
@@ -0,0 +1,272 @@
+package badge
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/narqo/go-badge"
+)
+
+// StateBadge is the last successful result of a badge.
+type StateBadge struct {
+	URL     string      `json:"url,omitempty"`
+	Link    string      `json:"link,omitempty"`
+	Title   string      `json:"title,omitempty"`
+	Label   string      `json:"label,omitempty"`
+	Message string      `json:"message,omitempty"`
+	Color   string      `json:"color,omitempty"`
+	Value   interface{} `json:"value,omitempty"`
+	Time    time.Time   `json:"time"`
+	// Head is the commit of the default branch the result was rendered for.
+	Head string `json:"head,omitempty"`
+	// Config is a hash of the configuration the result was rendered with.
+	Config string `json:"config,omitempty"`
+	// Reports are the files written next to the badge by path.
+	Reports map[string]string `json:"reports,omitempty"`
+}
+
+// StateProject is the state of a project in the last run.
+type StateProject struct {
+	Head    string     `json:"head,omitempty"`
+	Updated *time.Time `json:"updated,omitempty"`
+	// RepoConfig is the RepoConfigFile read at Head, empty if the project
+	// has none and nil if it was not read.
+	RepoConfig *string `json:"repo-config,omitempty"`
+}
+
+// State keeps the results of earlier runs, so failed badges can show their
+// last good value instead of an error and unchanged projects do not need to
+// be cloned again.
+type State struct {
+	lock     sync.Mutex
+	Projects map[string]StateProject `json:"projects,omitempty"`
+	Badges   map[string]StateBadge   `json:"badges"`
+}
+
+// NewState returns an empty state.
+func NewState() *State {
+	return &State{Projects: map[string]StateProject{}, Badges: map[string]StateBadge{}}
+}
+
+// LoadState reads a state file. A missing file is an empty state.
+func LoadState(path string) (*State, error) {
+	state := NewState()
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, state); err != nil {
+		return nil, fmt.Errorf("state file %s: %w", path, err)
+	}
+	if state.Projects == nil {
+		state.Projects = map[string]StateProject{}
+	}
+	if state.Badges == nil {
+		state.Badges = map[string]StateBadge{}
+	}
+	return state, nil
+}
+
+// Project returns the state of a project in the last run.
+func (s *State) Project(project Project) StateProject {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.Projects[project.URL]
+}
+
+// SetProject records the commit of the default branch of a project, the
+// time its forge last updated it and its RepoConfigFile, if it was read.
+func (s *State) SetProject(project Project, head string, updated time.Time, repoConfig []byte) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	state := StateProject{Head: head}
+	if !updated.IsZero() {
+		state.Updated = &updated
+	}
+	if repoConfig != nil {
+		content := string(repoConfig)
+		state.RepoConfig = &content
+	}
+	s.Projects[project.URL] = state
+}
+
+// configHash hashes the configuration of a project and the settings a badge
+// depends on.
+func configHash(project Project, name string) string {
+	var config interface{}
+	if f, ok := settings[name]; ok {
+		config = f()
+	}
+	b, err := json.Marshal(struct {
+		Project  Project
+		Settings interface{}
+	}{project, config})
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:8])
+}
+
+// writeReports writes the reports of a stored result again.
+func (b StateBadge) writeReports() error {
+	for path, content := range b.Reports {
+		if !strings.HasPrefix(path, "badges/") {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(filepath.FromSlash(path)), 0777); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(filepath.FromSlash(path), []byte(content), 0666); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Unchanged returns the last result of an incremental badge if it was
+// rendered for the current commit of the default branch with the same
+// configuration. Its reports are written again. The result is nil if the
+// badge did not apply to the project.
+func (s *State) Unchanged(project Project, name string) (*Badge, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	head := s.Projects[project.URL].Head
+	last, ok := s.Badges[stateKey(project, name)]
+	if !ok || head == "" || last.Head != head || !Incremental(name) {
+		return nil, false
+	}
+	if last.Config == "" || last.Config != configHash(project, name) {
+		return nil, false
+	}
+	if last.URL == "" {
+		return nil, true
+	}
+	if last.Label != "" && strings.HasPrefix(last.URL, "badges/") {
+		if err := writeSVG(filepath.FromSlash(last.URL), last.Label, last.Message, badge.Color(last.Color)); err != nil {
+			return nil, false
+		}
+	}
+	if err := last.writeReports(); err != nil {
+		return nil, false
+	}
+	return &Badge{
+		URL:     last.URL,
+		Link:    last.Link,
+		Title:   last.Title,
+		Value:   last.Value,
+		label:   last.Label,
+		message: last.Message,
+		color:   badge.Color(last.Color),
+	}, true
+}
+
+// Save writes the state file.
+func (s *State) Save(path string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, b, 0666)
+}
+
+func stateKey(project Project, name string) string {
+	return project.URL + " " + name
+}
+
+// Update records a successful badge and returns it. A failed badge, or a
+// badge that could not run because of err, is replaced by its last good
+// result marked as stale. The error is kept, so it is still reported.
+func (s *State) Update(project Project, name string, b *Badge, err error) *Badge {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	key := stateKey(project, name)
+	if b != nil && b.Error != nil {
+		err = b.Error
+	}
+	head := s.Projects[project.URL].Head
+	if err == nil {
+		// Badges that do not apply are remembered, so they are not
+		// rendered again for the same commit.
+		config := configHash(project, name)
+		if b == nil {
+			s.Badges[key] = StateBadge{Time: time.Now(), Head: head, Config: config}
+			return nil
+		}
+		var reports map[string]string
+		for path, content := range b.Reports {
+			if reports == nil {
+				reports = map[string]string{}
+			}
+			reports[path] = string(content)
+		}
+		s.Badges[key] = StateBadge{
+			URL:     b.URL,
+			Link:    b.Link,
+			Title:   b.Title,
+			Label:   b.label,
+			Message: b.message,
+			Color:   string(b.color),
+			Value:   b.Value,
+			Time:    time.Now(),
+			Head:    head,
+			Config:  config,
+			Reports: reports,
+		}
+		return b
+	}
+
+	last, ok := s.Badges[key]
+	if !ok || last.URL == "" {
+		return b
+	}
+	stale := &Badge{
+		URL:   last.URL,
+		Link:  last.Link,
+		Title: last.Title,
+		Error: Redact(err),
+		Value: last.Value,
+		Stale: last.Time,
+	}
+	if b != nil {
+		stale.Retries = b.Retries
+	}
+	// Badges rendered by the dashboard are rendered again with their age,
+	// external badges are linked as before.
+	if last.Label != "" && strings.HasPrefix(last.URL, "badges/") {
+		message := truncateMessage(fmt.Sprintf("%s (%s old)", last.Message, age(time.Since(last.Time))))
+		if err := writeSVG(filepath.FromSlash(last.URL), last.Label, message, badge.Color(last.Color)); err != nil {
+			return b
+		}
+	}
+	if err := last.writeReports(); err != nil {
+		return b
+	}
+	return stale
+}
+
+// age formats a duration in its largest unit, e.g. 3d.
+func age(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%dd", int(d/(24*time.Hour)))
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", int(d/time.Hour))
+	}
+	return fmt.Sprintf("%dm", int(d/time.Minute))
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cugu/dashboard/badge"
+)
+
+// Failure is a failed badge, or a failed step of preparing a project, in
+// errors.json.
+type Failure struct {
+	Category string     `json:"category,omitempty"`
+	Project  string     `json:"project"`
+	Badge    string     `json:"badge"`
+	Error    string     `json:"error"`
+	Stale    *time.Time `json:"stale,omitempty"`
+}
+
+// FailureGroup are the failures of the same cause.
+type FailureGroup struct {
+	Cause    string    `json:"cause"`
+	Remedy   string    `json:"remedy"`
+	Failures []Failure `json:"failures"`
+}
+
+// failures collects the errors of a run for the error report.
+type failures struct {
+	lock   sync.Mutex
+	causes map[string][]Failure
+}
+
+func (f *failures) add(category, project, badgeName string, err error, stale time.Time) {
+	failure := Failure{Category: category, Project: project, Badge: badgeName, Error: badge.Redact(err).Error()}
+	if !stale.IsZero() {
+		failure.Stale = &stale
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.causes == nil {
+		f.causes = map[string][]Failure{}
+	}
+	cause := badge.ErrorCause(err)
+	f.causes[cause] = append(f.causes[cause], failure)
+}
+
+func (f *failures) groups() []FailureGroup {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	var groups []FailureGroup
+	for _, cause := range badge.Causes {
+		list := f.causes[cause]
+		if len(list) == 0 {
+			continue
+		}
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].Project != list[j].Project {
+				return list[i].Project < list[j].Project
+			}
+			return list[i].Badge < list[j].Badge
+		})
+		groups = append(groups, FailureGroup{Cause: cause, Remedy: badge.Remedies[cause], Failures: list})
+	}
+	return groups
+}
+
+// logFailures logs a summary of every cause of failures with its remedy.
+func logFailures(groups []FailureGroup) {
+	for _, group := range groups {
+		var failed []string
+		for _, failure := range group.Failures {
+			failed = append(failed, failure.Project+" "+failure.Badge)
+		}
+		badge.Log.Error("badges failed, see errors.html", "cause", group.Cause, "count", len(group.Failures), "remedy", group.Remedy, "badges", strings.Join(failed, ", "))
+	}
+}
+
+func errorMarkdown(groups []FailureGroup) string {
+	if len(groups) == 0 {
+		return "# Errors\n\nNo badge failed.\n"
+	}
+	buf := "# Errors\n"
+	for _, group := range groups {
+		buf += fmt.Sprintf("\n## %s (%d)\n\n%s\n\n", group.Cause, len(group.Failures), group.Remedy)
+		buf += "| Project | Badge | Error | Shown result |\n| --- | --- | --- | --- |\n"
+		for _, failure := range group.Failures {
+			shown := "error"
+			if failure.Stale != nil {
+				shown = "from " + failure.Stale.Format("2006-01-02 15:04")
+			}
+			buf += fmt.Sprintf("| [%s](%s) | %s | %s | %s |\n", failure.Project, failure.Project, failure.Badge, markdownCell(failure.Error), shown)
+		}
+	}
+	return buf
+}
+
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	return strings.ReplaceAll(s, "\n", " ")
+}
+
+func createErrorReport(groups []FailureGroup) error {
+	if groups == nil {
+		groups = []FailureGroup{}
+	}
+	b, err := json.MarshalIndent(groups, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = ioutil.WriteFile("errors.json", b, 0666)
+	if err != nil {
+		return err
+	}
+	return createHTML("errors", []byte(errorMarkdown(groups)))
+}
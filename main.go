@@ -1,15 +1,19 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
-	"log"
 	"net/url"
 	"os"
+	"os/signal"
 	"path"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/markbates/pkger"
@@ -21,9 +25,17 @@ import (
 //go:generate pkger
 
 type Config struct {
-	Table      []Column   `yaml:"table,omitempty"`
-	Categories []Category `yaml:"categories,omitempty"`
-	StaticPath string     `yaml:"staticpath,omitempty"`
+	Table       []Column                     `yaml:"table,omitempty"`
+	Categories  []Category                   `yaml:"categories,omitempty"`
+	Commands    []badge.Command              `yaml:"commands,omitempty"`
+	Coverage    badge.CoverageThresholds     `yaml:"coverage,omitempty"`
+	Ageing      badge.AgeingThresholds       `yaml:"ageing,omitempty"`
+	Registries  badge.Registries             `yaml:"registries,omitempty"`
+	Licenses    badge.LicensePolicy          `yaml:"licenses,omitempty"`
+	Credentials map[string]badge.Credential  `yaml:"credentials,omitempty"`
+	HostWorkers map[string]int               `yaml:"host-workers,omitempty"`
+	Retry       map[string]badge.RetryPolicy `yaml:"retry,omitempty"`
+	StaticPath  string                       `yaml:"staticpath,omitempty"`
 }
 
 type Column struct {
@@ -38,87 +50,293 @@ type Category struct {
 }
 
 func main() {
-	log.SetFlags(log.Ldate | log.Ltime | log.Lshortfile)
-	
 	gitlabAccessToken := flag.String("gitlab", LookupEnvOrString("GITLAB_ACCESS_TOKEN"), "GitLab access token")
 	gitlabPushBadges := flag.Bool("gitlab-push-badges", strings.ToLower(LookupEnvOrString("GITLAB_PUSH_BADGES")) == "true", "push badges to GitLab")
 	githubAccessToken := flag.String("github", LookupEnvOrString("GITHUB_ACCESS_TOKEN"), "GitHub access token")
+	repoConfig := flag.Bool("repo-config", strings.ToLower(LookupEnvOrString("REPO_CONFIG")) != "false", "read "+badge.RepoConfigFile+" from every project")
+	secretRules := flag.String("secret-rules", LookupEnvOrString("SECRET_RULES"), "YAML file with rules for the secrets badge")
+	secretLocations := flag.Bool("secret-locations", strings.ToLower(LookupEnvOrString("SECRET_LOCATIONS")) == "true", "publish the files and lines of found secrets")
+	timeout := flag.Duration("timeout", LookupEnvOrDuration("TIMEOUT", 10*time.Minute), "deadline of the whole run")
+	badgeTimeout := flag.Duration("badge-timeout", LookupEnvOrDuration("BADGE_TIMEOUT", 5*time.Minute), "deadline of a single badge")
+	workers := flag.Int("workers", LookupEnvOrInt("WORKERS", 16), "number of badges rendered concurrently")
+	hostWorkers := flag.Int("host-workers", LookupEnvOrInt("HOST_WORKERS", 4), "number of badges rendered concurrently per host, 0 for no limit")
+	progress := flag.Bool("progress", strings.ToLower(LookupEnvOrString("PROGRESS")) != "false", "show the progress of the badges")
+	logFormat := flag.String("log-format", LookupEnvOrFallback("LOG_FORMAT", "text"), "format of the log, text or json")
+	logLevel := flag.String("log-level", LookupEnvOrFallback("LOG_LEVEL", "info"), "minimum level of the log, debug, info, warn or error")
+	verbose := flag.Bool("verbose", strings.ToLower(LookupEnvOrString("VERBOSE")) == "true", "log every request with its rate limit headers, same as -log-level debug")
+	serveAddr := flag.String("serve", LookupEnvOrString("SERVE"), "address to serve the metrics on /metrics, the dashboard is run every interval")
+	interval := flag.Duration("interval", LookupEnvOrDuration("INTERVAL", time.Hour), "time between the runs in serve mode")
+	full := flag.Bool("full", strings.ToLower(LookupEnvOrString("FULL")) == "true", "render all badges, also those of projects that did not change since the last run")
+	stateFile := flag.String("state", LookupEnvOrFallback("STATE", "state.json"), "file with the last good result of every badge, empty to disable")
 	flag.Parse()
 
-	if *githubAccessToken == "" {
-		log.Println("GitHub token not defined. GitHub Badges will not be available.")
-	} else {
-		badge.InitGitHubBadges(*githubAccessToken)
+	level, err := badge.ParseLevel(*logLevel)
+	if err != nil {
+		badge.Log.Fatal("invalid flag", "flag", "log-level", "error", err)
+	}
+	if *verbose {
+		level = badge.LevelDebug
+	}
+	if *logFormat != "text" && *logFormat != "json" {
+		badge.Log.Fatal("invalid flag", "flag", "log-format", "error", fmt.Errorf("unknown log format %q", *logFormat))
 	}
+	badge.Log = badge.NewLogger(os.Stderr, level, *logFormat == "json")
 
-	if *gitlabAccessToken == "" {
-		log.Println("GitLab token not defined. GitLab Badges will not be available.")
-	} else {
-		badge.InitGitLabBadges(*gitlabAccessToken)
+	if *secretRules != "" {
+		if err := badge.LoadSecretRules(*secretRules); err != nil {
+			badge.Log.Fatal("reading secret rules failed", "error", err)
+		}
 	}
+	badge.SecretLocations = *secretLocations
+
+	badge.SetDefaultToken("github", *githubAccessToken)
+	badge.SetDefaultToken("gitlab", *gitlabAccessToken)
 
 	badge.InitDefaultBadges()
 	badge.InitAzureBadges()
 	badge.InitMissingFileBadges()
 	badge.InitExternalCommandBadges()
+	badge.InitSARIFBadges()
+	badge.InitHistoryBadges()
+	badge.InitSlocBadges()
+	badge.InitCoverageBadges()
+	badge.InitReleaseBadges()
+	badge.InitOutdatedBadges()
+	badge.InitDockerfileBadges()
+	badge.InitLicenseBadges()
 	badge.Insecure = true
 
-	if err := run(*gitlabAccessToken, *githubAccessToken, *gitlabPushBadges); err != nil {
-		log.Fatal(err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	go func() {
+		<-interrupt
+		badge.Log.Warn("Interrupted, stopping all badges. Interrupt again to exit immediately.")
+		signal.Stop(interrupt)
+		cancel()
+	}()
+
+	opts := options{
+		GitLabPushBadges: *gitlabPushBadges,
+		RepoConfig:       *repoConfig,
+		BadgeTimeout:     *badgeTimeout,
+		Workers:          *workers,
+		HostWorkers:      *hostWorkers,
+		Progress:         *progress,
+		StateFile:        *stateFile,
+		Full:             *full,
+	}
+	if *serveAddr != "" {
+		if err := serve(ctx, *serveAddr, *interval); err != nil {
+			badge.Log.Fatal("serving metrics failed", "error", err)
+		}
+		return
+	}
+
+	ctx, cancelRun := context.WithTimeout(ctx, *timeout)
+	defer cancelRun()
+	if err := run(ctx, opts); err != nil {
+		badge.Log.Fatal("run failed", "error", err)
 	}
 }
 
-func run(gitlabAccessToken, githubAccessToken string, gitlabPushBadges bool) error {
+// options are the command line settings of a run.
+type options struct {
+	GitLabPushBadges bool
+	RepoConfig       bool
+	BadgeTimeout     time.Duration
+	Workers          int
+	HostWorkers      int
+	Progress         bool
+	StateFile        string
+	Full             bool
+}
+
+func run(ctx context.Context, opts options) error {
 	config, err := parseInput()
 	if err != nil {
 		return err
 	}
+	if config.Credentials != nil {
+		badge.Credentials = config.Credentials
+	}
+
+	if !badge.HasCredentials("github") && !projectTokens(config.Categories, "github") {
+		badge.Log.Info("GitHub token not defined. GitHub Badges will not be available.")
+	} else {
+		badge.InitGitHubBadges()
+	}
+
+	if !badge.HasCredentials("gitlab") && !projectTokens(config.Categories, "gitlab") {
+		badge.Log.Info("GitLab token not defined. GitLab Badges will not be available.")
+	} else {
+		badge.InitGitLabBadges()
+	}
+
+	badge.InitCommandBadges(config.Commands)
+	badge.Coverage = config.Coverage
+	badge.Ageing = config.Ageing
+	badge.Registry = config.Registries
+	badge.Licenses = config.Licenses
+	if config.Retry != nil {
+		badge.Retries = config.Retry
+	}
+
+	state := badge.NewState()
+	if opts.StateFile != "" {
+		state, err = badge.LoadState(opts.StateFile)
+		if err != nil {
+			return err
+		}
+	}
 
-	var wg sync.WaitGroup
 	var badges sync.Map
-	for _, category := range config.Categories {
-		for pID, project := range category.Projects {
-			project, err = parseProject(project, gitlabAccessToken, githubAccessToken)
+	var failed failures
+	work := func(ctx context.Context, j job) bool {
+		renderFunc, ok := badge.GetBadge(j.Badge)
+		log := badge.Log.With("project", j.Project.URL, "badge", j.Badge, "hoster", j.Project.Hoster)
+		if !ok {
+			failed.add(j.Category.Name, j.Project.URL, j.Badge, errors.New("badge missing"), time.Time{})
+			log.Error("badge missing")
+			return true
+		}
+		var b *badge.Badge
+		start := time.Now()
+		err := ctx.Err()
+		unchanged := false
+		if err == nil && !opts.Full {
+			b, unchanged = state.Unchanged(j.Project, j.Badge)
+		}
+		if err == nil && !unchanged {
+			b, err = renderBadge(badge.WithLogger(ctx, log), opts.BadgeTimeout, renderFunc, j.Project)
+		}
+		b = state.Update(j.Project, j.Badge, b, err)
+		if b != nil || err == nil {
+			badges.Store(j.Category.Name+j.Project.URL+j.Badge, b)
+		}
+		if b != nil && b.Error != nil {
+			err = b.Error
+		}
+		if err != nil {
+			var stale time.Time
+			if b != nil {
+				stale = b.Stale
+			}
+			failed.add(j.Category.Name, j.Project.URL, j.Badge, err, stale)
+			log.Warn("badge failed", "duration", time.Since(start), "cause", badge.ErrorCause(err), "error", err, "stale", !stale.IsZero())
+			return true
+		}
+		log.Debug("badge rendered", "duration", time.Since(start), "unchanged", unchanged)
+		return false
+	}
+
+	projectFailed := func(category string, project badge.Project, step string, err error) {
+		failed.add(category, project.URL, step, err, time.Time{})
+		badge.Log.Warn("project setup failed", "project", project.URL, "hoster", project.Hoster, "step", step, "cause", badge.ErrorCause(err), "error", err)
+	}
+
+	// setup prepares a project for its badges. The commit of the default
+	// branch is only looked up if the forge reports a change since the last
+	// run and the repository config is only read again for a new commit.
+	setup := func(category Category, project badge.Project) badge.Project {
+		project, err := parseProject(project)
+		if err != nil {
+			projectFailed(category.Name, project, "project", err)
+		}
+		project, err = badge.ResolveDefaultBranch(ctx, project)
+		if err != nil {
+			projectFailed(category.Name, project, "default branch", err)
+		}
+
+		last := state.Project(project)
+		var head string
+		var updated time.Time
+		if opts.StateFile != "" {
+			updated, err = badge.ProjectUpdated(ctx, project)
+			if err != nil {
+				projectFailed(category.Name, project, "updated", err)
+			}
+			if !updated.IsZero() && last.Updated != nil && last.Updated.Equal(updated) {
+				head = last.Head
+			}
+			if head == "" {
+				head, err = badge.HeadCommit(ctx, project)
+				if err != nil {
+					projectFailed(category.Name, project, "head", err)
+				}
+			}
+		}
+
+		var repoConfig []byte
+		if opts.RepoConfig {
+			if head != "" && head == last.Head && last.RepoConfig != nil && !opts.Full {
+				repoConfig = []byte(*last.RepoConfig)
+			} else {
+				repoConfig, err = badge.ReadRepoConfig(ctx, project)
+				if err != nil {
+					projectFailed(category.Name, project, badge.RepoConfigFile, err)
+				} else if repoConfig == nil {
+					repoConfig = []byte{}
+				}
+			}
+			project, err = badge.MergeRepoConfig(project, repoConfig)
 			if err != nil {
-				log.Println(err)
+				projectFailed(category.Name, project, badge.RepoConfigFile, err)
 			}
-			category.Projects[pID] = project
+		}
+		if opts.StateFile != "" {
+			state.SetProject(project, head, updated, repoConfig)
+		}
+
+		if project.GoImportPath == "" {
+			project.GoImportPath = project.Hoster + "/" + project.Namespace + "/" + project.Name
+		}
+		return project
+	}
+
+	workers := opts.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	var wg sync.WaitGroup
+	limit := make(chan struct{}, workers)
+	for _, category := range config.Categories {
+		for pID, project := range category.Projects {
+			wg.Add(1)
+			go func(category Category, pID int, project badge.Project) {
+				defer wg.Done()
+				limit <- struct{}{}
+				defer func() { <-limit }()
+				category.Projects[pID] = setup(category, project)
+			}(category, pID, project)
+		}
+	}
+	wg.Wait()
 
+	var jobs []job
+	for _, category := range config.Categories {
+		for _, project := range category.Projects {
 			for _, column := range config.Table {
 				for _, badgeName := range column.Enabled {
-					wg.Add(1)
-					go func(category Category, project badge.Project, badgeName string) {
-						defer wg.Done()
-						if !contains(project.Disable, badgeName) && !contains(project.Disable, column.Name) {
-							if renderFunc, ok := badge.GetBadge(badgeName); ok {
-								badges.Store(category.Name+project.URL+badgeName, renderFunc(project))
-							} else {
-								log.Println(badgeName + " badge missing")
-							}
-						}
-					}(category, project, badgeName)
+					if !contains(project.Disable, badgeName) && !contains(project.Disable, column.Name) {
+						jobs = append(jobs, job{Category: category, Project: project, Badge: badgeName})
+					}
 				}
 				for _, badgeName := range column.Disabled {
-					wg.Add(1)
-					go func(category Category, project badge.Project, badgeName string) {
-						defer wg.Done()
-						if contains(project.Enable, badgeName) {
-							if renderFunc, ok := badge.GetBadge(badgeName); ok {
-								badges.Store(category.Name+project.URL+badgeName, renderFunc(project))
-							} else {
-								log.Println(badgeName + " badge missing")
-							}
-						}
-					}(category, project, badgeName)
+					if contains(project.Enable, badgeName) {
+						jobs = append(jobs, job{Category: category, Project: project, Badge: badgeName})
+					}
 				}
 			}
 		}
 	}
 
-	if waitTimeout(&wg, 10*time.Minute) {
-		fmt.Println("Timed out waiting for wait group")
-	} else {
-		fmt.Println("Wait group finished")
+	newScheduler(opts.Workers, opts.HostWorkers, config.HostWorkers).run(ctx, jobs, opts.Progress, work)
+	if opts.StateFile != "" {
+		if err := state.Save(opts.StateFile); err != nil {
+			return err
+		}
 	}
 
 	err = os.MkdirAll("style", os.ModePerm)
@@ -159,53 +377,73 @@ func run(gitlabAccessToken, githubAccessToken string, gitlabPushBadges bool) err
 	if err != nil {
 		return err
 	}
+	results := collectResults(config.Categories, config.Table, &badges)
+	err = createResults(results)
+	if err != nil {
+		return err
+	}
+	err = createMetrics(results)
+	if err != nil {
+		return err
+	}
+	failures := failed.groups()
+	err = createErrorReport(failures)
+	if err != nil {
+		return err
+	}
+	logFailures(failures)
 
-	if gitlabPushBadges {
-		return createGitLabBadges(config.Categories, config.Table, &badges, gitlabAccessToken, config.StaticPath)
+	if opts.GitLabPushBadges {
+		if ctx.Err() != nil {
+			badge.Log.Warn("Run was cut off, badges are not pushed to GitLab")
+			return nil
+		}
+		return createGitLabBadges(ctx, config.Categories, config.Table, &badges, config.StaticPath)
 	}
 	return nil
 }
 
-// waitTimeout waits for the waitgroup for the specified max timeout.
-// Returns true if waiting timed out.
-func waitTimeout(wg *sync.WaitGroup, timeout time.Duration) bool {
-	c := make(chan struct{})
-	go func() {
-		defer close(c)
-		wg.Wait()
-	}()
-	select {
-	case <-c:
-		return false // completed normally
-	case <-time.After(timeout):
-		return true // timed out
+// renderBadge runs a badge function with its own deadline. The error is set
+// if the badge was cut off by its deadline, the deadline of the run or an
+// interrupt. The retries of the badge are counted in its result.
+func renderBadge(ctx context.Context, timeout time.Duration, renderFunc func(context.Context, badge.Project) *badge.Badge, project badge.Project) (*badge.Badge, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ctx, retries := badge.WithRetryCounter(ctx)
+	ctx, reports := badge.WithReports(ctx)
+	b := renderFunc(ctx, project)
+	if b != nil {
+		b.Retries = int(atomic.LoadInt32(retries))
+		b.Reports = reports.Files()
 	}
+	return b, ctx.Err()
 }
 
-func createGitLabBadges(categories []Category, table []Column, badges *sync.Map, gitlabAccessToken, staticPath string) error {
-	gl := badge.NewGitLabProject(gitlabAccessToken)
-
-	var isGitLab = func(p badge.Project) bool { return p.Hoster == "gitlab.com" || p.IsGitlab }
+func createGitLabBadges(ctx context.Context, categories []Category, table []Column, badges *sync.Map, staticPath string) error {
+	gl := badge.NewGitLabProject()
 
 	for _, category := range categories {
 		for _, project := range category.Projects {
-			if !isGitLab(project) {
+			if badge.Forge(project) != "gitlab" {
 				continue
 			}
 
 			id := strings.Trim(project.Namespace+"/"+project.Name, "/")
 
-			client, _ := gl.GetClient(project.Hoster)
+			client, err := gl.GetClient(project)
+			if err != nil {
+				return err
+			}
 
 			// delete all old badges
-			oldBadges, _, err := client.ProjectBadges.ListProjectBadges(id, &gitlab.ListProjectBadgesOptions{})
+			oldBadges, _, err := client.ProjectBadges.ListProjectBadges(id, &gitlab.ListProjectBadgesOptions{}, gitlab.WithContext(ctx))
 			if err != nil {
 				return err
 			}
 
 			authError := false
 			for _, b := range oldBadges {
-				_, err = client.ProjectBadges.DeleteProjectBadge(id, b.ID)
+				_, err = client.ProjectBadges.DeleteProjectBadge(id, b.ID, gitlab.WithContext(ctx))
 				if err != nil {
 					authError = true
 					break
@@ -234,7 +472,7 @@ func createGitLabBadges(categories []Category, table []Column, badges *sync.Map,
 						}
 
 						opt := &gitlab.AddProjectBadgeOptions{LinkURL: &l, ImageURL: &u}
-						_, _, err := client.ProjectBadges.AddProjectBadge(id, opt)
+						_, _, err := client.ProjectBadges.AddProjectBadge(id, opt, gitlab.WithContext(ctx))
 						if err != nil {
 							return err
 						}
@@ -247,28 +485,32 @@ func createGitLabBadges(categories []Category, table []Column, badges *sync.Map,
 	return nil
 }
 
-func parseProject(project badge.Project, gitlabAccessToken, githubAccessToken string) (badge.Project, error) {
+func parseProject(project badge.Project) (badge.Project, error) {
 	u, err := url.Parse(project.URL)
 	if err != nil {
 		return badge.Project{}, err
 	}
 	project.Hoster = u.Host
 
-	if project.Hoster == "gitlab.com" || project.IsGitlab {
-		project.Token = gitlabAccessToken
-	}
-	if project.Hoster == "github.com" {
-		project.Token = githubAccessToken
-	}
-
 	project.Namespace = strings.TrimLeft(path.Dir(u.Path), "/")
 	project.Name = path.Base(u.Path)
-	if project.GoImportPath == "" {
-		project.GoImportPath = project.Hoster + "/" + project.Namespace + "/" + project.Name
-	}
 	return project, nil
 }
 
+// projectTokens reports whether a project on a host of the forge configures
+// its own token.
+func projectTokens(categories []Category, forge string) bool {
+	for _, category := range categories {
+		for _, project := range category.Projects {
+			project, err := parseProject(project)
+			if err == nil && project.TokenFrom != nil && badge.Forge(project) == forge {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func contains(s []string, e string) bool {
 	for _, a := range s {
 		if strings.EqualFold(a, e) {
@@ -278,6 +520,33 @@ func contains(s []string, e string) bool {
 	return false
 }
 
+func LookupEnvOrDuration(key string, fallback time.Duration) time.Duration {
+	if val, ok := os.LookupEnv(key); ok {
+		if d, err := time.ParseDuration(val); err == nil {
+			return d
+		}
+		badge.Log.Warn("invalid duration", "env", key, "value", val)
+	}
+	return fallback
+}
+
+func LookupEnvOrInt(key string, fallback int) int {
+	if val, ok := os.LookupEnv(key); ok {
+		if i, err := strconv.Atoi(val); err == nil {
+			return i
+		}
+		badge.Log.Warn("invalid number", "env", key, "value", val)
+	}
+	return fallback
+}
+
+func LookupEnvOrFallback(key, fallback string) string {
+	if val, ok := os.LookupEnv(key); ok {
+		return val
+	}
+	return fallback
+}
+
 func LookupEnvOrString(key string) string {
 	if val, ok := os.LookupEnv(key); ok {
 		return val
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cugu/dashboard/badge"
+)
+
+const metricsFile = "metrics.prom"
+
+// numericValue converts the value of a badge to a float, values from the state
+// file are already floats.
+func numericValue(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func metricLabels(result Result) string {
+	return fmt.Sprintf(`category="%s",project="%s",hoster="%s",badge="%s"`,
+		labelEscaper.Replace(result.Category), labelEscaper.Replace(result.Project),
+		labelEscaper.Replace(result.Hoster), labelEscaper.Replace(result.Badge))
+}
+
+// formatMetrics renders the results in the Prometheus text format. Badges
+// with a numeric value are exported as dashboard_badge_value, all badges as
+// dashboard_badge_failed.
+func formatMetrics(results []Result, now time.Time) []byte {
+	var b bytes.Buffer
+	b.WriteString("# HELP dashboard_badge_value Numeric value of a badge.\n")
+	b.WriteString("# TYPE dashboard_badge_value gauge\n")
+	for _, result := range results {
+		if value, ok := numericValue(result.Value); ok {
+			fmt.Fprintf(&b, "dashboard_badge_value{%s} %s\n", metricLabels(result), strconv.FormatFloat(value, 'g', -1, 64))
+		}
+	}
+	b.WriteString("# HELP dashboard_badge_failed Whether a badge failed, its value may be stale.\n")
+	b.WriteString("# TYPE dashboard_badge_failed gauge\n")
+	for _, result := range results {
+		failed := 0
+		if result.Error != "" {
+			failed = 1
+		}
+		fmt.Fprintf(&b, "dashboard_badge_failed{%s} %d\n", metricLabels(result), failed)
+	}
+	b.WriteString("# HELP dashboard_last_run_timestamp_seconds Time of the last run.\n")
+	b.WriteString("# TYPE dashboard_last_run_timestamp_seconds gauge\n")
+	fmt.Fprintf(&b, "dashboard_last_run_timestamp_seconds %d\n", now.Unix())
+	return b.Bytes()
+}
+
+// createMetrics writes the metrics for the textfile collector of the node
+// exporter. The file is replaced at once, so it is never read half written.
+func createMetrics(results []Result) error {
+	err := ioutil.WriteFile(metricsFile+".tmp", formatMetrics(results, time.Now()), 0666)
+	if err != nil {
+		return err
+	}
+	return os.Rename(metricsFile+".tmp", metricsFile)
+}
+
+// tokenFlags are passed to runs in the environment variables they default
+// to, so tokens do not show up in the process list.
+var tokenFlags = map[string]string{"github": "GITHUB_ACCESS_TOKEN", "gitlab": "GITLAB_ACCESS_TOKEN"}
+
+// childArgs returns the arguments and environment of the current process for
+// a run without serve mode.
+func childArgs() (args []string, env []string) {
+	env = os.Environ()
+	flag.Visit(func(f *flag.Flag) {
+		switch {
+		case f.Name == "serve" || f.Name == "interval":
+		case tokenFlags[f.Name] != "":
+			env = append(env, tokenFlags[f.Name]+"="+f.Value.String())
+		default:
+			args = append(args, "-"+f.Name+"="+f.Value.String())
+		}
+	})
+	return append(append(args, "-serve="), flag.Args()...), env
+}
+
+// serve runs the dashboard every interval and serves the metrics of the last
+// run on /metrics. Every run is a new process, so no caches are shared
+// between runs.
+func serve(ctx context.Context, addr string, interval time.Duration) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		b, err := ioutil.ReadFile(metricsFile)
+		if os.IsNotExist(err) {
+			http.Error(w, "no run finished yet", http.StatusServiceUnavailable)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		_, _ = w.Write(b)
+	})
+	server := &http.Server{Addr: addr, Handler: mux}
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+
+	go func() {
+		executable, err := os.Executable()
+		if err != nil {
+			executable = os.Args[0]
+		}
+		args, env := childArgs()
+		for {
+			start := time.Now()
+			cmd := exec.CommandContext(ctx, executable, args...)
+			cmd.Stdout, cmd.Stderr, cmd.Env = os.Stdout, os.Stderr, env
+			err := cmd.Run()
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				badge.Log.Error("run failed", "duration", time.Since(start), "error", err)
+			} else {
+				badge.Log.Info("run finished", "duration", time.Since(start))
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(interval):
+			}
+		}
+	}()
+
+	badge.Log.Info("serving metrics", "addr", addr, "interval", interval)
+	err := server.ListenAndServe()
+	if err == http.ErrServerClosed {
+		return nil
+	}
+	return err
+}
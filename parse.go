@@ -5,9 +5,16 @@ import (
 	"io/ioutil"
 
 	"gopkg.in/yaml.v2"
+
+	"github.com/cugu/dashboard/badge"
 )
 
 func parseInput() (config Config, err error) {
+	config.Coverage = badge.Coverage
+	config.Ageing = badge.Ageing
+	config.Registries = badge.Registry
+	config.Licenses = badge.Licenses
+
 	yamlFile, err := ioutil.ReadFile(flag.Args()[0])
 	if err != nil {
 		return
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	"github.com/cugu/dashboard/badge"
+)
+
+// Result is the structured form of a single badge in results.json.
+type Result struct {
+	Category string      `json:"category"`
+	Project  string      `json:"project"`
+	Hoster   string      `json:"hoster"`
+	Badge    string      `json:"badge"`
+	URL      string      `json:"url,omitempty"`
+	Link     string      `json:"link,omitempty"`
+	Error    string      `json:"error,omitempty"`
+	Value    interface{} `json:"value,omitempty"`
+	Retries  int         `json:"retries,omitempty"`
+	Stale    *time.Time  `json:"stale,omitempty"`
+}
+
+func collectResults(categories []Category, table []Column, badges *sync.Map) []Result {
+	var results []Result
+	for _, category := range categories {
+		for _, project := range category.Projects {
+			for _, column := range table {
+				for _, badgeName := range append(column.Enabled, column.Disabled...) {
+					b, ok := badges.Load(category.Name + project.URL + badgeName)
+					if !ok || b.(*badge.Badge) == nil {
+						continue
+					}
+					pBadge := b.(*badge.Badge)
+					result := Result{
+						Category: category.Name,
+						Project:  project.URL,
+						Hoster:   project.Hoster,
+						Badge:    badgeName,
+						URL:      pBadge.URL,
+						Link:     pBadge.Link,
+						Value:    pBadge.Value,
+						Retries:  pBadge.Retries,
+					}
+					if pBadge.Error != nil {
+						result.Error = pBadge.Error.Error()
+					}
+					if !pBadge.Stale.IsZero() {
+						result.Stale = &pBadge.Stale
+					}
+					results = append(results, result)
+				}
+			}
+		}
+	}
+	return results
+}
+
+func createResults(results []Result) error {
+	b, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile("results.json", b, 0666)
+}
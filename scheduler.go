@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cugu/dashboard/badge"
+)
+
+type job struct {
+	Category Category
+	Project  badge.Project
+	Badge    string
+}
+
+// scheduler runs jobs on a fixed number of workers. Cheap badges are started
+// first and every host is limited to a number of concurrent jobs.
+type scheduler struct {
+	workers     int
+	hostWorkers int
+	hostLimits  map[string]int
+
+	lock    sync.Mutex
+	cond    *sync.Cond
+	pending []job
+	running map[string]int
+	done    int
+	failed  int
+}
+
+func newScheduler(workers, hostWorkers int, hostLimits map[string]int) *scheduler {
+	if workers < 1 {
+		workers = 1
+	}
+	s := &scheduler{workers: workers, hostWorkers: hostWorkers, hostLimits: hostLimits, running: map[string]int{}}
+	s.cond = sync.NewCond(&s.lock)
+	return s
+}
+
+func (s *scheduler) hostLimit(host string) int {
+	if limit, ok := s.hostLimits[host]; ok && limit > 0 {
+		return limit
+	}
+	return s.hostWorkers
+}
+
+// next blocks until a job of a host below its limit is pending. It returns
+// false when no jobs are left.
+func (s *scheduler) next() (job, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for len(s.pending) > 0 {
+		for i, j := range s.pending {
+			limit := s.hostLimit(j.Project.Hoster)
+			if limit > 0 && s.running[j.Project.Hoster] >= limit {
+				continue
+			}
+			s.pending = append(s.pending[:i], s.pending[i+1:]...)
+			s.running[j.Project.Hoster]++
+			return j, true
+		}
+		s.cond.Wait()
+	}
+	return job{}, false
+}
+
+func (s *scheduler) finish(j job, failed bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.running[j.Project.Hoster]--
+	s.done++
+	if failed {
+		s.failed++
+	}
+	s.cond.Broadcast()
+}
+
+// counts returns the number of done, running, pending and failed jobs.
+func (s *scheduler) counts() (done, running, pending, failed int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, count := range s.running {
+		running += count
+	}
+	return s.done, running, len(s.pending), s.failed
+}
+
+func (s *scheduler) status() string {
+	done, running, pending, failed := s.counts()
+	return fmt.Sprintf("badges: %d done, %d running, %d pending, %d failed", done, running, pending, failed)
+}
+
+func (s *scheduler) logStatus(msg string) {
+	done, running, pending, failed := s.counts()
+	badge.Log.Info(msg, "done", done, "running", running, "pending", pending, "failed", failed)
+}
+
+// run executes all jobs and returns when they are done. The result of work
+// reports whether a badge failed.
+func (s *scheduler) run(ctx context.Context, jobs []job, progress bool, work func(context.Context, job) bool) {
+	sort.SliceStable(jobs, func(i, k int) bool { return badge.GetCost(jobs[i].Badge) < badge.GetCost(jobs[k].Badge) })
+	s.pending = jobs
+
+	stop := make(chan struct{})
+	if progress {
+		go s.report(stop)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < s.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				j, ok := s.next()
+				if !ok {
+					return
+				}
+				s.finish(j, work(ctx, j))
+			}
+		}()
+	}
+	wg.Wait()
+
+	close(stop)
+	if progress && terminal() {
+		fmt.Fprintf(os.Stderr, "\r%s\x1b[K\n", s.status())
+	}
+	s.logStatus("badges finished")
+}
+
+func terminal() bool {
+	info, err := os.Stderr.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// report prints the status of the scheduler. Terminals get a single updated
+// line, other outputs a log entry every ten seconds.
+func (s *scheduler) report(stop chan struct{}) {
+	tty := terminal()
+	interval := 10 * time.Second
+	if tty {
+		interval = 500 * time.Millisecond
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if tty {
+				fmt.Fprintf(os.Stderr, "\r%s\x1b[K", s.status())
+			} else {
+				s.logStatus("progress")
+			}
+		}
+	}
+}